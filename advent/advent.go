@@ -52,6 +52,26 @@ func (g *Game) EndLocationSpan() {
 	}
 }
 
+// SpanContext returns the context of the span for where the player is, or the
+// root context before the first location span starts.
+func (g *Game) SpanContext() context.Context {
+	if g.LocationCtx != nil {
+		return g.LocationCtx
+	}
+	return g.Ctx
+}
+
+// welcome returns the text a new game opens with.
+func (g *Game) welcome() string {
+	msg := g.localise(dungeon.Arbitrary_Messages[dungeon.WELCOME_YOU])
+
+	// The original printed this while it loaded the database
+	if g.Settings.OldStyle {
+		msg = OLDSTYLE_BANNER + "\n" + msg
+	}
+	return msg
+}
+
 func NewGame(seed int, restoreFileName string, autoSaveFileName string, logFileName string, debug bool, oldStyle bool, autoSave bool, scripts []string) Game {
 
 	game := Game{}
@@ -64,6 +84,7 @@ func NewGame(seed int, restoreFileName string, autoSaveFileName string, logFileN
 	game.Settings.OldStyle = oldStyle
 	game.Settings.Autosave = autoSave
 	game.Settings.Scripts = scripts
+	game.Settings.UndoDepth = UNDO_DEPTH
 
 	game.Loc = int32(dungeon.LOC_START)
 	game.Newloc = int32(dungeon.LOC_START)
@@ -75,17 +96,12 @@ func NewGame(seed int, restoreFileName string, autoSaveFileName string, logFileN
 	game.Abbnum = 5
 	game.Foobar = WORD_EMPTY
 
-	// Initial Welcom
-	game.Output = dungeon.Arbitrary_Messages[dungeon.WELCOME_YOU]
-
 	if debug {
 		fmt.Println("Debug mode enabled")
 	}
 
-	// TODP: Decide if we want this or not
-	if oldStyle {
-		fmt.Println("Oldstyle mode enable. Does nothing at the moment.")
-	}
+	// Initial Welcom
+	game.Output = game.welcome()
 
 	if logFileName != "" {
 
@@ -460,9 +476,7 @@ func (g *Game) CheckHints() {
 
 						game.rspeak(int32(dungeon.HINT_COST), dungeon.Hints[hint].Penalty)
 
-						game.Output = game.Output + "\n\n" + dungeon.Arbitrary_Messages[dungeon.WANT_HINT]
-
-						game.AskQuestion(game.Output, wantHint)
+						game.AskQuestion(dungeon.Arbitrary_Messages[dungeon.WANT_HINT], wantHint)
 
 						return response
 					}
@@ -500,6 +514,7 @@ func (g *Game) DescribeLocation() {
 
 func (g *Game) DoMove() bool {
 	toLoc := g.Newloc
+	from := g.Loc
 
 	// End current location span and start a new one for the new location
 	g.StartLocationSpan(toLoc)
@@ -533,6 +548,12 @@ func (g *Game) DoMove() bool {
 
 	g.Loc = g.Newloc
 
+	// Record where the motion took the player for the MAP command
+	if g.moveMapping {
+		g.recordTransition(from, g.moveMotion)
+		g.moveMapping = false
+	}
+
 	if !g.dwarfmove() {
 		g.croak()
 		return false
@@ -545,7 +566,7 @@ func (g *Game) DoMove() bool {
 		return false
 	}
 
-	if !forced(g.Loc) && g.dark() && g.Wzdark && pct(PIT_KILL_PROB) {
+	if !forced(g.Loc) && g.dark() && g.Wzdark && g.pct(PIT_KILL_PROB) {
 		g.rspeak(int32(dungeon.PIT_FALL))
 		g.Oldlc2 = g.Loc
 		g.croak()
@@ -566,63 +587,39 @@ func (g *Game) sspeak(msg int, args ...any) {
 
 func (g *Game) rspeak(vocab int32, args ...any) error {
 	msg, err := g.vspeak(dungeon.Arbitrary_Messages[vocab], false, args...)
-
-	if err != nil {
-		return err
-	} else {
-
-		if g.Output != "" {
-			g.Output = g.Output + "\n\n" + msg
-		} else {
-			g.Output = msg
-		}
-
-		return nil
-	}
+	g.appendOutput(msg)
+	return err
 }
 
 // Speak a temporary message
 func (g *Game) tspeak(vocab int32, args ...any) error {
 	msg, err := g.vspeak(dungeon.Arbitrary_Messages[vocab], false, args...)
-
-	if err != nil {
-		return err
-	} else {
-
-		g.OutputType = 1
-		if g.Output != "" {
-			g.Output = g.Output + "\n\n" + msg
-		} else {
-			g.Output = msg
-		}
-
-		return nil
-	}
+	g.OutputType = 1
+	g.appendOutput(msg)
+	return err
 }
 
 // Speak a specified string
 func (g *Game) speak(msg string, args ...any) error {
 	msg, err := g.vspeak(msg, false, args...)
+	g.appendOutput(msg)
+	return err
+}
 
-	if err != nil {
-		return err
-
+// appendOutput adds a paragraph to the output of the turn.
+func (g *Game) appendOutput(msg string) {
+	if g.Output != "" {
+		g.Output = g.Output + "\n\n" + msg
 	} else {
-
-		if g.Output != "" {
-			g.Output = g.Output + "\n\n" + msg
-		} else {
-			g.Output = msg
-		}
-		return nil
+		g.Output = msg
 	}
 }
 
 // TODO: Refactor pSepak. In fact refactor all the speak routines
 func (g *Game) pSpeak(msg int32, mode SpeakType, blank bool, skip int32, args ...any) {
 
-	var err error
 	var output string
+	var err error
 
 	// Bounds check for object index
 	if msg < 0 || int(msg) >= len(dungeon.Objects) {
@@ -659,15 +656,16 @@ func (g *Game) pSpeak(msg int32, mode SpeakType, blank bool, skip int32, args ..
 
 	}
 
-	if err != nil {
-		g.Output = fmt.Sprintf("Error: %s", err.Error())
-	} else {
-		g.Output = g.Output + "\n\n" + output
+	// Messages are checked by ValidateMessages, so anything left unrendered
+	// shows in the text
+	if err != nil && g.Settings.EnableDebug {
+		fmt.Printf("DEBUG pSpeak: object %d: %v\n", msg, err)
 	}
-
+	g.Output = g.Output + "\n\n" + output
 }
 
-// TODO: This can probably be refactored to be more go like
+// vspeak renders a message in the game's locale with its arguments. The
+// text is returned even when it can't all be rendered, along with the error.
 func (g *Game) vspeak(msg string, blank bool, args ...any) (string, error) {
 
 	if msg == "" {
@@ -680,88 +678,17 @@ func (g *Game) vspeak(msg string, blank bool, args ...any) (string, error) {
 		prefix = "\n"
 	}
 
-	pluralise := false
-
-	renderedString := msg
+	renderedString := g.localise(msg)
 
-	// If location is outside. Render the string with "ground" instead of "floor"
-	if strings.Contains(renderedString, "floor") && !inside(g.Loc) {
+	// If location is outside. Render the string with "ground" instead of
+	// "floor", unless in oldstyle, which keeps the original wording
+	if strings.Contains(renderedString, "floor") && !inside(g.Loc) && !g.Settings.OldStyle {
 		renderedString = strings.Replace(renderedString, "floor", "ground", -1)
 	}
 
-	if strings.Contains(renderedString, "%d") {
-
-		digits := findOccurrences(renderedString, "%d")
-
-		// if we are rendering digits into a string then the passed args
-		// should all be digits.
-
-		if len(args) != len(digits) {
-			return "", fmt.Errorf("error: Number of digits in string does not match number of args")
-		}
-
-		// replace %d in the string with the args in the order they appear in the
-		// passed args
-		strVals := make([]string, len(args))
-		for i, arg := range args {
-
-			value, ok := arg.(int)
-			if ok {
-				stringValue := fmt.Sprintf("%d", value)
-
-				// More than one thing, so we should pularise the string
-				if value > 1 {
-					pluralise = true
-				}
-
-				strVals[i] = stringValue
-			} else {
-				return "", fmt.Errorf("error: Argument %d is not an int", i)
-			}
-
-		}
-
-		renderedString = replaceOccurrences(renderedString, "%d", strVals)
-
-	}
-
-	if strings.Contains(renderedString, "%s") {
-		strings := findOccurrences("%s", renderedString)
-
-		// if we are rendering strings into a string then the passed args
-		// should all be strings.
-
-		if len(args) != len(strings) {
-			return "", fmt.Errorf("error: Number of strings in string does not match number of args")
-		}
-
-		// replace %s in the string with the args in the order they appear in the
-		// passed args
-		strVals := make([]string, len(args))
-		for i, arg := range args {
-
-			value, ok := arg.(string)
-			if ok {
-				strVals[i] = value
-			} else {
-				return "", fmt.Errorf("error: Argument %d is not a string", i)
-			}
-
-		}
-
-		renderedString = replaceOccurrences(renderedString, "%s", strVals)
-	}
-
-	if strings.Contains(renderedString, "%S") {
-		if pluralise {
-			renderedString = strings.Replace(renderedString, "%S", "s", -1)
-		} else {
-			renderedString = strings.Replace(renderedString, "%S", "", -1)
-		}
-	}
-	// g.Output = renderedString
+	renderedString, err := formatMessage(renderedString, args...)
 
-	return prefix + renderedString, nil
+	return prefix + renderedString, err
 }
 
 /*
@@ -888,14 +815,14 @@ func (g *Game) dwarfmove() bool {
 	 *  the 5 dwarves.  If any of the survivors is at game.loc,
 	 *  replace them with the alternate. */
 	if g.Dflag == 1 {
-		if !indeep(g.Loc) || pct(95) && (!condbit(g.Loc, dungeon.COND_NOBACK) || pct(85)) {
+		if !indeep(g.Loc) || g.pct(95) && (!condbit(g.Loc, dungeon.COND_NOBACK) || g.pct(85)) {
 			return true
 		}
 
 		g.Dflag = 2
 		for i := 1; i <= 2; i++ {
 			j := 1 + g.randRange(dungeon.NDWARVES-1)
-			if pct(50) {
+			if g.pct(50) {
 				g.Dwarves[j].Loc = 0
 			}
 		}
@@ -1019,7 +946,7 @@ func (g *Game) dwarfmove() bool {
 	}
 
 	if g.Dtotal == 1 {
-		g.rspeak(int32(dungeon.DWARF_SINGLE), g.Dtotal)
+		g.rspeak(int32(dungeon.DWARF_SINGLE))
 	} else {
 		g.rspeak(int32(dungeon.DWARF_PACK), g.Dtotal)
 	}
@@ -1038,9 +965,9 @@ func (g *Game) dwarfmove() bool {
 		if stick > 1 {
 			g.rspeak(int32(dungeon.MULTIPLE_HITS), stick)
 		} else if stick == 1 {
-			g.rspeak(int32(dungeon.ONE_HIT), stick)
+			g.rspeak(int32(dungeon.ONE_HIT))
 		} else {
-			g.rspeak(int32(dungeon.NONE_HIT), stick)
+			g.rspeak(int32(dungeon.NONE_HIT))
 		}
 	} else {
 		g.rspeak(int32(dungeon.KNIFE_THROWN))
@@ -1142,7 +1069,7 @@ func (g *Game) spottedByPirate(dwarfNum int) bool {
 			g.Dwarves[PIRATE].Oldloc = g.Chloc
 			g.Dwarves[PIRATE].Seen = false
 		} else {
-			if g.Dwarves[PIRATE].Oldloc != g.Dwarves[PIRATE].Loc && pct(20) {
+			if g.Dwarves[PIRATE].Oldloc != g.Dwarves[PIRATE].Loc && g.pct(20) {
 				g.rspeak(int32(dungeon.PIRATE_RUSTLES))
 			}
 		}
@@ -1297,6 +1224,9 @@ func (g *Game) PlayerMove(motion int32) {
 	travelEntry := int(dungeon.TKey[g.Loc])
 	g.Newloc = g.Loc
 
+	// Remember the motion so DoMove can map it once the player gets there
+	defer func() { g.moveMotion, g.moveMapping = motion, g.Newloc != g.Loc }()
+
 	if travelEntry == 0 {
 		// BUG: Location has no travel entries
 		return
@@ -1419,7 +1349,7 @@ func (g *Game) PlayerMove(motion int32) {
 				if condtype < dungeon.CondNot {
 					/* YAML N and [pct N] conditionals */
 					if condtype == dungeon.CondGoto || condtype == dungeon.CondPct {
-						if condarg1 == 0 || pct(int32(condarg1)) {
+						if condarg1 == 0 || g.pct(int32(condarg1)) {
 							break
 						}
 						/* else fall through */
@@ -1536,6 +1466,8 @@ func (g *Game) PlayerMove(motion int32) {
 }
 
 func (g *Game) ListObjects() {
+	defer g.updateNotebook()
+
 	if !g.dark() {
 
 		// TODO: Figure out how to handle this better
@@ -1662,9 +1594,11 @@ func (g *Game) atDwrf(where int32) int {
 
 // Utility Functions
 
-// Checks if a randomly generated number between 0 and 99 is less than N
-func pct(n int32) bool {
-	return (rand.Int31n(100) < n)
+// Checks if a randomly generated number between 0 and 99 is less than N.
+// The number comes from the game's own generator, as in the original, so a
+// game plays out the same way from the same seed.
+func (g *Game) pct(n int32) bool {
+	return g.randRange(100) < n
 }
 
 // SaveStructToFile saves the struct to a file in JSON format.
@@ -1731,61 +1665,6 @@ func getNextLCGValue(lcgX int32) int32 {
 	return (LCG_A*lcgX + LCG_C) % LCG_M
 }
 
-func findOccurrences(haystack, needle string) []int {
-	var indices []int
-	offset := 0
-
-	for {
-		// Find the index of the substring starting from the current offset
-		index := strings.Index(haystack[offset:], needle)
-		if index == -1 {
-			break // No more occurrences
-		}
-
-		// Add the absolute index to the result
-		absoluteIndex := offset + index
-		indices = append(indices, absoluteIndex)
-
-		// Move the offset forward to continue searching
-		offset = absoluteIndex + len(needle)
-	}
-
-	return indices
-}
-
-func replaceOccurrences(haystack, needle string, replacements []string) string {
-	var result strings.Builder
-	offset := 0
-	replacementIndex := 0
-
-	for {
-		// Find the next occurrence of the substring
-		index := strings.Index(haystack[offset:], needle)
-		if index == -1 {
-			// No more occurrences, append the rest of the string
-			result.WriteString(haystack[offset:])
-			break
-		}
-
-		// Append the part of the string before the match
-		result.WriteString(haystack[offset : offset+index])
-
-		// Append the replacement string
-		if replacementIndex < len(replacements) {
-			result.WriteString(replacements[replacementIndex])
-			replacementIndex++
-		} else {
-			// If no more replacements are available, append the original substring
-			result.WriteString(needle)
-		}
-
-		// Move the offset past the current match
-		offset += index + len(needle)
-	}
-
-	return result.String()
-}
-
 func replaceAtIndex(haystack string, index int, length int, replacement string) (string, error) {
 	// Ensure the index and length are within bounds
 	if index < 0 || index+length > len(haystack) {
@@ -17,7 +17,17 @@ type Game struct {
 	ScriptCommands []string `json:"-"` // Commands to execute from script
 	ScriptIndex    int      `json:"-"` // Current position in script
 
-	QueryFlag bool
+	// Undo state
+	Snapshots []Snapshot `json:"-"` // States before recent turns, oldest first
+	Branches  []Branch   `json:"-"` // Abandoned lines of play from the timeline
+	Undos     int32      // Number of turns undone so far
+
+	locale *Locale // Language of the game text, nil for English
+
+	moveMotion  int32 // The motion PlayerMove set Newloc by, for DoMove to map
+	moveMapping bool  // Set until DoMove has mapped moveMotion
+
+	QueryFlag       bool
 	QueryResponse   string
 	OnQueryResponse func(response string, game *Game) string `json:"-"`
 	Output          string
@@ -54,6 +64,7 @@ type Game struct {
 	Oldloc          int32
 	Oldlc2          int32
 	Oldobj          int32
+	Itobj           int32 // The object "it" stands for
 	Saved           int32
 	Tally           int32
 	Thresh          int32
@@ -87,6 +98,9 @@ type Game struct {
 	}
 	Link [dungeon.NOBJECTS*2 + 1]int32
 
+	MapEdges []MapEdge // Moves the player has made, for the MAP command
+	Notebook Notebook  // What the player has learned, for the AI player
+
 	Settings Settings
 }
 
@@ -125,6 +139,8 @@ const (
 
 	TOKLEN         = 5
 	WORD_NOT_FOUND = -1
+
+	OLDSTYLE_BANNER = "Initialising..."
 )
 
 type CmdState int
@@ -169,6 +185,8 @@ type Command struct {
 	Verb     int
 	Obj      int
 	CmdState CmdState
+	All      bool  // GET ALL or DROP ALL
+	Except   []int // Objects left out of ALL
 }
 
 type Save struct {
@@ -187,6 +205,11 @@ type Settings struct {
 	RestoreFileName  string
 	EnableDebug      bool
 	Scripts          []string
+	UndoDepth        int  // Number of turns that can be undone
+	NoUndo           bool // Disable UNDO for 'purist' runs
+	AutoCorrect      bool // Replace misspelt words with the nearest known word
+	NoNormalise      bool // Parse commands exactly as typed, without rewriting everyday English
+	TriedExitsOnly   bool // EXITS lists only the ways the player has already gone
 }
 
 type Travel struct {
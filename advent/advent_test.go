@@ -173,17 +173,28 @@ func TestGetNextLCGValue(t *testing.T) {
 
 // TestPct tests the percentage function
 func TestPct(t *testing.T) {
+	game := NewGame(1, "", "", "", false, false, false, nil)
+
 	// pct(100) should always return true
 	for i := 0; i < 100; i++ {
-		if !pct(100) {
+		if !game.pct(100) {
 			t.Error("pct(100) should always return true")
 		}
 	}
 
 	// pct(0) should always return false
 	for i := 0; i < 100; i++ {
-		if pct(0) {
+		if game.pct(0) {
 			t.Error("pct(0) should always return false")
 		}
 	}
+
+	// The same seed gives the same chances
+	game = NewGame(1, "", "", "", false, false, false, nil)
+	other := NewGame(1, "", "", "", false, false, false, nil)
+	for i := 0; i < 100; i++ {
+		if game.pct(50) != other.pct(50) {
+			t.Fatal("pct should follow the game's seed")
+		}
+	}
 }
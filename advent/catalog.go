@@ -0,0 +1,275 @@
+package advent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+const (
+	LOCALE_DIR    = "locales" // Where -lang looks for <lang>.json
+	PSEUDO_LOCALE = "pseudo"  // Built in locale that marks every string
+)
+
+// Catalog holds the text of a locale. Messages are keyed on the English text
+// they replace, whether it comes from the dungeon (arbitrary messages,
+// location descriptions, object states, hints) or from the Go code, so a
+// catalog doesn't depend on the order of the generated tables. Words replace
+// the vocabulary of a motion, object or action, keyed on its first English
+// word.
+type Catalog struct {
+	Language string            `json:"language"`
+	Messages map[string]string `json:"messages"`
+	Words    CatalogWords      `json:"words"`
+
+	// transform, when set, rewrites every message instead of Messages
+	transform func(string) string
+}
+
+// CatalogWords are the vocabulary overrides of a locale.
+type CatalogWords struct {
+	Motions map[string][]string `json:"motions"`
+	Objects map[string][]string `json:"objects"`
+	Actions map[string][]string `json:"actions"`
+}
+
+// englishWords keeps the dungeon's own vocabulary, motions, objects and
+// actions, for locales to start from.
+var englishWords = sync.OnceValue(func() [3][]dungeon.String_Group_t {
+	return tableWords(dungeon.Motions, dungeon.Objects, dungeon.Actions)
+})
+
+// Locale is a catalog made ready for play: its text, and the vocabulary with
+// its word overrides in place. A game speaks and parses in its own locale,
+// so games in different languages can run side by side; the dungeon tables
+// are never changed.
+type Locale struct {
+	catalog *Catalog
+	words   [3][]dungeon.String_Group_t // Motion, object and action words
+
+	vocabulary func() *Vocabulary
+	adjectives func() map[string][]int
+}
+
+// english is the dungeon's own text, the locale of a game that hasn't been
+// given one.
+var english = sync.OnceValue(func() *Locale { return NewLocale(nil) })
+
+// NewLocale prepares a catalog for play, or English when c is nil.
+func NewLocale(c *Catalog) *Locale {
+	l := &Locale{catalog: c, words: englishWords()}
+
+	if c != nil {
+		l.words[0] = replaceWords(c.Words.Motions, l.words[0])
+		l.words[1] = replaceWords(c.Words.Objects, l.words[1])
+		l.words[2] = replaceWords(c.Words.Actions, l.words[2])
+	}
+
+	l.vocabulary = sync.OnceValue(func() *Vocabulary { return newVocabulary(l.words) })
+	l.adjectives = sync.OnceValue(l.inventoryAdjectives)
+	return l
+}
+
+// localise returns the text of a message in the locale.
+func (l *Locale) localise(msg string) string {
+	if l.catalog == nil || msg == "" {
+		return msg
+	}
+	if l.catalog.transform != nil {
+		return l.catalog.transform(msg)
+	}
+	if text, ok := l.catalog.Messages[msg]; ok {
+		return text
+	}
+	return msg
+}
+
+// fullWord returns a vocabulary word as a player would write it. Words the
+// catalog gives are already as it wants them; only the dungeon's own are cut
+// short.
+func (l *Locale) fullWord(word string) string {
+	if l.catalog != nil {
+		for _, table := range []map[string][]string{l.catalog.Words.Motions, l.catalog.Words.Objects, l.catalog.Words.Actions} {
+			for _, words := range table {
+				if slices.ContainsFunc(words, func(w string) bool { return strings.EqualFold(w, word) }) {
+					return word
+				}
+			}
+		}
+	}
+	return fullWord(word)
+}
+
+// motionWords, objectWords and actionWords return the vocabulary of a
+// motion, object or action in the locale.
+func (l *Locale) motionWords(motion int) dungeon.String_Group_t { return l.words[0][motion] }
+func (l *Locale) objectWords(obj int) dungeon.String_Group_t    { return l.words[1][obj] }
+func (l *Locale) actionWords(action int) dungeon.String_Group_t { return l.words[2][action] }
+
+// language returns the locale the game speaks.
+func (g *Game) language() *Locale {
+	if g.locale == nil {
+		return english()
+	}
+	return g.locale
+}
+
+// localise returns the text of a message in the game's locale.
+func (g *Game) localise(msg string) string {
+	return g.language().localise(msg)
+}
+
+// UseLocale switches the game to a locale, or back to English when l is nil,
+// and says the opening text again in it: the welcome of a new game, or where
+// the player is in a restored one. It should be called before the first
+// command.
+func (g *Game) UseLocale(l *Locale) {
+	g.locale = l
+
+	if g.Settings.NewGame {
+		g.Output = g.welcome()
+		return
+	}
+	g.Output = ""
+	g.DescribeLocation()
+	g.ListObjects()
+}
+
+// replaceWords returns a copy of groups with the words of every group whose
+// first English word is overridden replaced.
+func replaceWords(overrides map[string][]string, groups []dungeon.String_Group_t) []dungeon.String_Group_t {
+	groups = slices.Clone(groups)
+	for i, group := range groups {
+		if len(group.Strs) == 0 {
+			continue
+		}
+		words, ok := overrides[strings.ToLower(group.Strs[0])]
+		if !ok {
+			continue
+		}
+		strs := make([]string, len(words))
+		for j, word := range words {
+			strs[j] = strings.ToLower(word)
+		}
+		groups[i] = dungeon.String_Group_t{Strs: strs, N: len(strs)}
+	}
+	return groups
+}
+
+// LoadLocale finds the catalog for a -lang setting: "" or "en" for English,
+// "pseudo" for the pseudo-locale, a catalog file, or the name of a file in
+// LOCALE_DIR.
+func LoadLocale(lang string) (*Catalog, error) {
+	switch lang {
+	case "", "en":
+		return nil, nil
+	case PSEUDO_LOCALE:
+		return PseudoCatalog(), nil
+	}
+
+	path := lang
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(LOCALE_DIR, lang+".json")
+	}
+	return LoadCatalog(path)
+}
+
+// LoadCatalog reads a locale file and checks it against the dungeon.
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading locale: %w", err)
+	}
+
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing locale %s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("locale %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Validate checks that each message keeps the placeholders of the English
+// text it replaces, %S included, and that each word override names a real
+// vocabulary group.
+func (c *Catalog) Validate() error {
+	for english, text := range c.Messages {
+		if conversionsOf(english, "dsS") != conversionsOf(text, "dsS") {
+			return fmt.Errorf("message %q: placeholders don't match %q", text, english)
+		}
+	}
+
+	original := englishWords()
+	sections := []struct {
+		name      string
+		overrides map[string][]string
+		groups    []dungeon.String_Group_t
+	}{
+		{"motion", c.Words.Motions, original[0]},
+		{"object", c.Words.Objects, original[1]},
+		{"action", c.Words.Actions, original[2]},
+	}
+	for _, section := range sections {
+		for word, words := range section.overrides {
+			if len(words) == 0 {
+				return fmt.Errorf("%s %q has no words", section.name, word)
+			}
+			if !hasGroup(section.groups, word) {
+				return fmt.Errorf("%s %q is not in the vocabulary", section.name, word)
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasGroup(groups []dungeon.String_Group_t, word string) bool {
+	for _, group := range groups {
+		if len(group.Strs) > 0 && strings.EqualFold(group.Strs[0], word) {
+			return true
+		}
+	}
+	return false
+}
+
+// PseudoCatalog returns the pseudo-locale: every message is bracketed and
+// its letters accented, leaving placeholders alone, so any text that doesn't
+// go through the catalog stands out. The vocabulary is unchanged.
+func PseudoCatalog() *Catalog {
+	return &Catalog{Language: PSEUDO_LOCALE, transform: pseudoLocalise}
+}
+
+var pseudoLetters = strings.NewReplacer(
+	"a", "á", "e", "é", "i", "í", "o", "ó", "u", "ú", "c", "ç", "n", "ñ", "y", "ý",
+	"A", "Á", "E", "É", "I", "Í", "O", "Ó", "U", "Ú", "C", "Ç", "N", "Ñ", "Y", "Ý",
+)
+
+func pseudoLocalise(msg string) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < len(msg); {
+		// Placeholders are left for vspeak
+		if msg[i] == '%' && i+1 < len(msg) {
+			b.WriteString(msg[i : i+2])
+			i += 2
+			continue
+		}
+		j := strings.IndexByte(msg[i:], '%')
+		if j < 0 || i+j == len(msg)-1 {
+			j = len(msg) - i
+		}
+		b.WriteString(pseudoLetters.Replace(msg[i : i+j]))
+		i += j
+	}
+	b.WriteString("]")
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package advent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// writeLocale writes a locale file for a test and returns its path.
+func writeLocale(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write locale: %v", err)
+	}
+	return path
+}
+
+func TestPseudoLocaleCoversGameText(t *testing.T) {
+	game := newStartedGame()
+	game.UseLocale(NewLocale(PseudoCatalog()))
+
+	for _, command := range []string{"look", "east", "get lamp", "inventory", "get all", "frobnicate", "exits", "drop it", "undo", "quit"} {
+		game.ProcessCommand(command)
+		if game.Newloc != game.Loc {
+			game.DoMove()
+			game.DescribeLocation()
+			game.ListObjects()
+		}
+
+		for _, paragraph := range strings.Split(game.Output, "\n\n") {
+			paragraph = strings.TrimSpace(paragraph)
+			if paragraph != "" && !strings.HasPrefix(paragraph, "[") {
+				t.Errorf("%q: text %q didn't go through the catalog", command, paragraph)
+			}
+		}
+	}
+}
+
+func TestPseudoLocaleCoversAccessors(t *testing.T) {
+	game := newStartedGame()
+	game.UseLocale(NewLocale(PseudoCatalog()))
+	game.ProcessCommand("east")
+	game.DoMove()
+
+	texts := append([]string{game.GetLocationDescription()}, game.GetVisibleObjects()...)
+	for _, entry := range game.Timeline() {
+		texts = append(texts, entry.LocName)
+	}
+	for _, text := range texts {
+		if !strings.HasPrefix(text, "[") {
+			t.Errorf("%q didn't go through the catalog", text)
+		}
+	}
+	if len(texts) < 3 {
+		t.Errorf("Expected a location, an object and a timeline entry, got %q", texts)
+	}
+}
+
+func TestUseLocaleSaysWelcomeAgain(t *testing.T) {
+	game := newTestGame()
+	game.UseLocale(NewLocale(PseudoCatalog()))
+
+	if want := pseudoLocalise(dungeon.Arbitrary_Messages[dungeon.WELCOME_YOU]); game.Output != want {
+		t.Errorf("Expected the welcome in the locale, got %q", game.Output)
+	}
+}
+
+func TestPseudoLocaliseKeepsPlaceholders(t *testing.T) {
+	got := pseudoLocalise("You have %d turn%S left, %s.")
+	want := "[Ýóú hávé %d túrñ%S léft, %s.]"
+
+	if got != want {
+		t.Errorf("pseudoLocalise = %q, want %q", got, want)
+	}
+}
+
+func TestCatalogMessagesAndWords(t *testing.T) {
+	path := writeLocale(t, `{
+		"language": "fr",
+		"messages": {"OK": "D'accord.", "Brass lantern": "Lanterne en laiton"},
+		"words": {"objects": {"lamp": ["lampe", "lanterne"]}}
+	}`)
+
+	c, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog returned error: %v", err)
+	}
+
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+	game.Objects[dungeon.LAMP].Prop = 0
+	game.UseLocale(NewLocale(c))
+	game.ProcessCommand("prendre lampe")
+	game.ProcessCommand("get lampe")
+
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Errorf("'get lampe' should get the lamp, got place=%d", game.Objects[dungeon.LAMP].Place)
+	}
+	if game.Output != "D'accord." {
+		t.Errorf("Expected the French OK, got %q", game.Output)
+	}
+	if items := game.InventoryDescriptions(); len(items) != 1 || items[0] != "Lanterne en laiton" {
+		t.Errorf("Expected the French lamp in the inventory, got %v", items)
+	}
+
+	game.ProcessCommand("drop lamp")
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Error("LAMP should no longer be a word once the locale replaces it")
+	}
+}
+
+func TestLocalesAreKeptApart(t *testing.T) {
+	c := &Catalog{
+		Messages: map[string]string{"OK": "D'accord."},
+		Words:    CatalogWords{Objects: map[string][]string{"lamp": {"lampe"}}},
+	}
+	fr, en := newStartedGame(), newStartedGame()
+	fr.UseLocale(NewLocale(c))
+	for _, game := range []*Game{fr, en} {
+		game.Objects[dungeon.LAMP].Place = game.Loc
+		game.Objects[dungeon.LAMP].Prop = 0
+	}
+
+	fr.ProcessCommand("get lampe")
+	en.ProcessCommand("get lamp")
+
+	if fr.Output != "D'accord." || en.Output != "OK" {
+		t.Errorf("Each game should speak its own language, got %q and %q", fr.Output, en.Output)
+	}
+	if en.getVocabMetaData("LAMPE").ID != WORD_NOT_FOUND {
+		t.Error("LAMPE should not be a word in the English game")
+	}
+	if dungeon.Objects[dungeon.LAMP].Words.Strs[0] != "lamp" {
+		t.Errorf("The dungeon tables should be left alone, got %q", dungeon.Objects[dungeon.LAMP].Words.Strs[0])
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog Catalog
+	}{
+		{"missing placeholder", Catalog{Messages: map[string]string{"Game saved to %s.": "Partie sauvegardée."}}},
+		{"missing plural", Catalog{Messages: map[string]string{"Undone %d turn%S.": "%d tour annulé."}}},
+		{"unknown word", Catalog{Words: CatalogWords{Objects: map[string][]string{"sword": {"épée"}}}}},
+		{"no words", Catalog{Words: CatalogWords{Actions: map[string][]string{"get": {}}}}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.catalog.Validate(); err == nil {
+			t.Errorf("%s: expected Validate to fail", tt.name)
+		}
+	}
+}
+
+func TestLoadLocale(t *testing.T) {
+	if c, err := LoadLocale(""); c != nil || err != nil {
+		t.Errorf("Expected English for no language, got %v, %v", c, err)
+	}
+	if c, err := LoadLocale(PSEUDO_LOCALE); err != nil || c.Language != PSEUDO_LOCALE {
+		t.Errorf("Expected the pseudo-locale, got %v, %v", c, err)
+	}
+	if _, err := LoadLocale("klingon"); err == nil {
+		t.Error("Expected an error for a missing locale")
+	}
+}
@@ -0,0 +1,378 @@
+package advent
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+const (
+	MAP_RADIUS     = 2  // Cells either side of the current location in the MAP command
+	MAP_CELL_WIDTH = 14 // Width of a location label in the MAP command
+	MAP_GAP        = 3  // Width of the connector between two cells
+
+	// Maze rooms are drawn as a single node per maze
+	mazeAlikeNode     = -1
+	mazeDifferentNode = -2
+)
+
+// MapEdge is a transition between two locations that the player has made.
+type MapEdge struct {
+	From   int32
+	Motion int32
+	To     int32
+}
+
+type mapCell struct {
+	x, y int
+}
+
+// compassOffsets gives the grid offset for each compass motion.
+var compassOffsets = map[int32]mapCell{
+	int32(dungeon.NORTH): {0, -1},
+	int32(dungeon.SOUTH): {0, 1},
+	int32(dungeon.EAST):  {1, 0},
+	int32(dungeon.WEST):  {-1, 0},
+	int32(dungeon.NE):    {1, -1},
+	int32(dungeon.NW):    {-1, -1},
+	int32(dungeon.SE):    {1, 1},
+	int32(dungeon.SW):    {-1, 1},
+}
+
+// recordTransition adds the move from a location by a motion to the map, if
+// it took the player somewhere new.
+func (g *Game) recordTransition(from int32, motion int32) {
+	to := g.Loc
+	if to <= int32(dungeon.LOC_NOWHERE) || to == from || motion < 0 || motion >= dungeon.NMOTIONS {
+		return
+	}
+
+	edge := MapEdge{From: from, Motion: motion, To: to}
+	for _, e := range g.MapEdges {
+		if e == edge {
+			return
+		}
+	}
+
+	g.MapEdges = append(g.MapEdges, edge)
+}
+
+// ShowMap renders the explored map around the current location as game output.
+func (g *Game) ShowMap() {
+	msg := g.MapGrid(MAP_RADIUS, MAP_CELL_WIDTH)
+	if legend := g.MapLegend(); legend != "" {
+		msg += "\n\n" + legend
+	}
+
+	if g.Output != "" {
+		g.Output = g.Output + "\n\n" + msg
+	} else {
+		g.Output = msg
+	}
+}
+
+// MapGrid draws the explored locations within radius cells of the current
+// location, joined by the compass directions the player has travelled.
+// The current location is drawn in braces.
+func (g *Game) MapGrid(radius int, cellWidth int) string {
+	if cellWidth < 3 {
+		cellWidth = 3
+	}
+
+	placed := g.layoutMap(radius)
+
+	minX, minY, maxX, maxY := radius, radius, radius, radius
+	for _, c := range placed {
+		minX, maxX = min(minX, c.x), max(maxX, c.x)
+		minY, maxY = min(minY, c.y), max(maxY, c.y)
+	}
+
+	width := (maxX-minX+1)*cellWidth + (maxX-minX)*MAP_GAP
+	height := (maxY-minY+1)*2 - 1
+
+	canvas := make([][]rune, height)
+	for i := range canvas {
+		canvas[i] = []rune(strings.Repeat(" ", width))
+	}
+
+	col := func(x int) int { return (x - minX) * (cellWidth + MAP_GAP) }
+	row := func(y int) int { return (y - minY) * 2 }
+
+	// Connectors first so the labels sit on top of them
+	for _, e := range g.MapEdges {
+		off, ok := compassOffsets[e.Motion]
+		if !ok {
+			continue
+		}
+		a, aok := placed[mapNode(e.From)]
+		b, bok := placed[mapNode(e.To)]
+		if !aok || !bok || b.x-a.x != off.x || b.y-a.y != off.y {
+			continue
+		}
+
+		left := min(a.x, b.x)
+		top := min(a.y, b.y)
+		switch {
+		case off.y == 0:
+			for i := 0; i < MAP_GAP; i++ {
+				canvas[row(a.y)][col(left)+cellWidth+i] = '─'
+			}
+		case off.x == 0:
+			canvas[row(top)+1][col(a.x)+cellWidth/2] = '│'
+		case off.x == off.y:
+			canvas[row(top)+1][col(left)+cellWidth+MAP_GAP/2] = '╲'
+		default:
+			canvas[row(top)+1][col(left)+cellWidth+MAP_GAP/2] = '╱'
+		}
+	}
+
+	current := mapNode(g.Loc)
+	for node, c := range placed {
+		opening, closing := '[', ']'
+		if node == current {
+			opening, closing = '{', '}'
+		}
+
+		label := []rune(g.mapLabel(node))
+		if len(label) > cellWidth-2 {
+			label = append(label[:cellWidth-3], '…')
+		}
+
+		cell := []rune(strings.Repeat(" ", cellWidth))
+		cell[0] = opening
+		copy(cell[1:], label)
+		cell[cellWidth-1] = closing
+		copy(canvas[row(c.y)][col(c.x):], cell)
+	}
+
+	lines := make([]string, len(canvas))
+	for i, line := range canvas {
+		lines[i] = strings.TrimRight(string(line), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// MapLegend lists the ways out of the current location that the grid can't
+// show, the mazes explored and the objects lying in explored locations.
+func (g *Game) MapLegend() string {
+	var sections []string
+
+	current := mapNode(g.Loc)
+	placed := g.layoutMap(MAP_RADIUS)
+
+	var exits []string
+	seen := make(map[string]bool)
+	for _, e := range g.MapEdges {
+		if mapNode(e.From) != current || mapNode(e.To) == current {
+			continue
+		}
+		// Skip exits the grid already draws
+		if off, ok := compassOffsets[e.Motion]; ok {
+			here := placed[current]
+			if there, drawn := placed[mapNode(e.To)]; drawn && there.x-here.x == off.x && there.y-here.y == off.y {
+				continue
+			}
+		}
+		exit := fmt.Sprintf("  %s → %s", g.motionName(e.Motion), g.mapLabel(mapNode(e.To)))
+		if !seen[exit] {
+			seen[exit] = true
+			exits = append(exits, exit)
+		}
+	}
+	if len(exits) > 0 {
+		sections = append(sections, g.localise("Other ways from here:")+"\n"+strings.Join(exits, "\n"))
+	}
+
+	// Group the explored maze rooms and note anything dropped in them
+	var notes []string
+	for _, node := range []int32{mazeAlikeNode, mazeDifferentNode} {
+		rooms := g.exploredRooms(node)
+		if len(rooms) == 0 {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf(g.localise("%s: %d room%s explored"), g.mapLabel(node), len(rooms), plural(len(rooms))))
+		for _, loc := range rooms {
+			if objs := g.objectsAt(loc); len(objs) > 0 {
+				notes = append(notes, fmt.Sprintf("  "+g.localise("room %d: %s"), loc, strings.Join(objs, ", ")))
+			}
+		}
+	}
+
+	for _, loc := range g.exploredRooms(0) {
+		if objs := g.objectsAt(loc); len(objs) > 0 {
+			notes = append(notes, fmt.Sprintf("%s: %s", g.mapLabel(loc), strings.Join(objs, ", ")))
+		}
+	}
+	if len(notes) > 0 {
+		sections = append(sections, strings.Join(notes, "\n"))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// layoutMap places explored nodes on a (2*radius+1) square grid, starting
+// from the current location and following compass moves outwards.
+func (g *Game) layoutMap(radius int) map[int32]mapCell {
+	size := 2*radius + 1
+	center := mapNode(g.Loc)
+
+	placed := map[int32]mapCell{center: {radius, radius}}
+	taken := map[mapCell]bool{{radius, radius}: true}
+	queue := []int32{center}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		here := placed[node]
+
+		for _, e := range g.MapEdges {
+			off, ok := compassOffsets[e.Motion]
+			if !ok {
+				continue
+			}
+
+			from, to := mapNode(e.From), mapNode(e.To)
+			var next int32
+			switch {
+			case from == to:
+				continue
+			case from == node:
+				next = to
+			case to == node:
+				// Travelling back the way the player came
+				next = from
+				off = mapCell{-off.x, -off.y}
+			default:
+				continue
+			}
+
+			if _, done := placed[next]; done {
+				continue
+			}
+
+			c := mapCell{here.x + off.x, here.y + off.y}
+			if c.x < 0 || c.y < 0 || c.x >= size || c.y >= size || taken[c] {
+				continue
+			}
+
+			placed[next] = c
+			taken[c] = true
+			queue = append(queue, next)
+		}
+	}
+
+	return placed
+}
+
+// exploredRooms returns the explored locations belonging to a maze node, or
+// those outside any maze when node is 0.
+func (g *Game) exploredRooms(node int32) []int32 {
+	seen := map[int32]bool{g.Loc: true}
+	for _, e := range g.MapEdges {
+		seen[e.From] = true
+		seen[e.To] = true
+	}
+
+	var rooms []int32
+	for loc := range seen {
+		n := mapNode(loc)
+		if (node == 0 && n == loc) || (node != 0 && n == node) {
+			rooms = append(rooms, loc)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i] < rooms[j] })
+
+	return rooms
+}
+
+// objectsAt names the free objects lying at a location that the player has found.
+func (g *Game) objectsAt(loc int32) []string {
+	var objs []string
+	for i := 1; i <= dungeon.NOBJECTS; i++ {
+		if g.Objects[i].Place != loc || g.Objects[i].Fixed != IS_FREE || g.objectIsNotFound(i) {
+			continue
+		}
+		if words := g.language().objectWords(i); len(words.Strs) > 0 && words.Strs[0] != "" {
+			objs = append(objs, strings.ToLower(words.Strs[0]))
+		}
+	}
+	return objs
+}
+
+// mapNode returns the map node for a location, grouping maze rooms together.
+func mapNode(loc int32) int32 {
+	if loc <= 0 || loc > dungeon.NLOCATIONS {
+		return loc
+	}
+	if condbit(loc, dungeon.COND_ALLALIKE) {
+		return mazeAlikeNode
+	}
+	if condbit(loc, dungeon.COND_ALLDIFFERENT) {
+		return mazeDifferentNode
+	}
+	return loc
+}
+
+// mapLabel returns a short label for a map node, derived from the location's
+// short description (or the first sentence of its long one).
+func (g *Game) mapLabel(node int32) string {
+	switch node {
+	case mazeAlikeNode:
+		return g.localise("Maze (all alike)")
+	case mazeDifferentNode:
+		return g.localise("Maze (all different)")
+	}
+	if node <= 0 || int(node) >= len(dungeon.Locations) {
+		return "Nowhere"
+	}
+
+	label := g.localise(dungeon.Locations[node].Description.Small)
+	if label == "" {
+		label = g.localise(dungeon.Locations[node].Description.Big)
+	}
+	if i := strings.IndexAny(label, ".\n"); i >= 0 {
+		label = label[:i]
+	}
+
+	label = strings.NewReplacer("\\", "", "\"", "").Replace(label)
+	for _, prefix := range []string{"You're ", "You are ", "in ", "at ", "on ", "a ", "an ", "the "} {
+		label = strings.TrimPrefix(label, prefix)
+	}
+
+	if label == "" {
+		return fmt.Sprintf("Location %d", node)
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// compassWords are the motion words players know best, named in preference
+// to the others a motion has: DOWN rather than D or DOWNWARD.
+var compassWords = []string{"north", "south", "east", "west", "ne", "se", "sw", "nw", "up", "down", "in", "out"}
+
+// motionName returns the word a motion is best known by, written in full.
+func (g *Game) motionName(motion int32) string {
+	if motion < 0 || int(motion) >= len(dungeon.Motions) {
+		return "?"
+	}
+	words := g.language().motionWords(int(motion))
+	if len(words.Strs) == 0 {
+		return "?"
+	}
+	for _, word := range words.Strs {
+		if slices.Contains(compassWords, strings.ToLower(word)) {
+			return strings.ToUpper(word)
+		}
+	}
+	return strings.ToUpper(g.language().fullWord(words.Strs[0]))
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
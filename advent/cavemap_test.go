@@ -0,0 +1,142 @@
+package advent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestRecordTransition tests that moves are recorded once on the map
+func TestRecordTransition(t *testing.T) {
+	game := newStartedGame()
+	startLoc := game.Loc
+
+	game.ProcessCommand("east")
+	game.DoMove()
+	game.ProcessCommand("west")
+	game.DoMove()
+	game.ProcessCommand("east")
+	game.DoMove()
+
+	if len(game.MapEdges) != 2 {
+		t.Fatalf("MapEdges: got %d, want 2: %+v", len(game.MapEdges), game.MapEdges)
+	}
+
+	want := MapEdge{From: startLoc, Motion: int32(dungeon.EAST), To: game.Loc}
+	if game.MapEdges[0] != want {
+		t.Errorf("First edge: got %+v, want %+v", game.MapEdges[0], want)
+	}
+}
+
+// TestRecordTransitionBlockedByDwarf tests that a move a dwarf blocks isn't
+// put on the map
+func TestRecordTransitionBlockedByDwarf(t *testing.T) {
+	game := newStartedGame()
+	game.carry(int32(dungeon.LAMP), game.Loc)
+	game.Objects[dungeon.LAMP].Prop = dungeon.LAMP_BRIGHT
+	game.Loc = int32(dungeon.LOC_MISTHALL)
+	game.Newloc = game.Loc
+
+	exits := game.Exits()
+	if len(exits) == 0 {
+		t.Fatal("Expected exits from the hall of mists")
+	}
+	game.Dwarves[1].Oldloc = exits[0].Destination
+	game.Dwarves[1].Seen = false
+
+	game.PlayerMove(exits[0].Motion)
+	game.DoMove()
+
+	if game.Loc != int32(dungeon.LOC_MISTHALL) {
+		t.Fatalf("The dwarf should have blocked the move, player is at %d", game.Loc)
+	}
+	if len(game.MapEdges) != 0 {
+		t.Errorf("A blocked move should not be mapped, got %+v", game.MapEdges)
+	}
+}
+
+// TestMapGrid tests that the current location is drawn in braces next to its neighbour
+func TestMapGrid(t *testing.T) {
+	game := newStartedGame()
+	game.ProcessCommand("east")
+	game.DoMove()
+
+	grid := game.MapGrid(MAP_RADIUS, MAP_CELL_WIDTH)
+	if !strings.Contains(grid, "{") || !strings.Contains(grid, "[") {
+		t.Errorf("Expected current and neighbouring locations in grid:\n%s", grid)
+	}
+	if !strings.Contains(grid, "─") {
+		t.Errorf("Expected an east-west connector in grid:\n%s", grid)
+	}
+
+	lines := strings.Split(grid, "\n")
+	if len(lines) != 1 {
+		t.Errorf("Grid height: got %d lines, want 1:\n%s", len(lines), grid)
+	}
+}
+
+// TestMapGroupsMazes tests that maze rooms share a single map node
+func TestMapGroupsMazes(t *testing.T) {
+	var alike []int32
+	for loc := int32(1); loc <= dungeon.NLOCATIONS; loc++ {
+		if condbit(loc, dungeon.COND_ALLALIKE) {
+			alike = append(alike, loc)
+		}
+	}
+	if len(alike) < 2 {
+		t.Fatal("Expected several all-alike maze rooms in the dungeon")
+	}
+
+	for _, loc := range alike {
+		if mapNode(loc) != mazeAlikeNode {
+			t.Errorf("mapNode(%d): got %d, want %d", loc, mapNode(loc), mazeAlikeNode)
+		}
+	}
+	if mapNode(int32(dungeon.LOC_START)) != int32(dungeon.LOC_START) {
+		t.Error("Locations outside mazes should be their own node")
+	}
+}
+
+// TestMapLegendMazeObjects tests that objects dropped in a maze are noted by room
+func TestMapLegendMazeObjects(t *testing.T) {
+	game := newStartedGame()
+
+	var maze int32
+	for loc := int32(1); loc <= dungeon.NLOCATIONS; loc++ {
+		if condbit(loc, dungeon.COND_ALLALIKE) {
+			maze = loc
+			break
+		}
+	}
+
+	game.MapEdges = append(game.MapEdges, MapEdge{From: game.Loc, Motion: int32(dungeon.DOWN), To: maze})
+	game.Objects[dungeon.LAMP].Place = maze
+	game.Objects[dungeon.LAMP].Prop = STATE_FOUND
+
+	legend := game.MapLegend()
+	if !strings.Contains(legend, "Maze (all alike): 1 room explored") {
+		t.Errorf("Expected maze summary in legend:\n%s", legend)
+	}
+	if !strings.Contains(legend, "lamp") {
+		t.Errorf("Expected the dropped lamp in legend:\n%s", legend)
+	}
+}
+
+// TestMapCommand tests that MAP shows the map without using a turn
+func TestMapCommand(t *testing.T) {
+	game := newStartedGame()
+	game.ProcessCommand("east")
+	game.DoMove()
+
+	turns := game.Turns
+	game.Output = ""
+	game.ProcessCommand("map")
+
+	if game.Turns != turns {
+		t.Errorf("MAP should not use a turn: got %d, want %d", game.Turns, turns)
+	}
+	if !strings.Contains(game.Output, "{") {
+		t.Errorf("Expected map in output, got %q", game.Output)
+	}
+}
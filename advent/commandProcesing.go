@@ -34,24 +34,14 @@ func (g *Game) tokeniseCommand(command string) Command {
 	 * possible an emulation of the original UI.
 	 */
 
-	// Leaving this in here in case I want to implement 'oldstyle' mode later.
-	/*
-			 if (settings.oldstyle) {
-				cmd->word[0].raw[TOKLEN + TOKLEN] =
-				    cmd->word[1].raw[TOKLEN + TOKLEN] = '\0';
-				for (size_t i = 0; i < strlen(cmd->word[0].raw); i++) {
-					cmd->word[0].raw[i] = toupper(cmd->word[0].raw[i]);
-				}
-				for (size_t i = 0; i < strlen(cmd->word[1].raw); i++) {
-					cmd->word[1].raw[i] = toupper(cmd->word[1].raw[i]);
-				}
-		}
-	*/
 	words := []Command_Word{}
 
 	for _, word := range SplitWords(command) {
 		tmpWord := Command_Word{}
 		tmpWord.Raw = strings.ToUpper(word)
+		if g.Settings.OldStyle && len(tmpWord.Raw) > TOKLEN+TOKLEN {
+			tmpWord.Raw = tmpWord.Raw[:TOKLEN+TOKLEN]
+		}
 		tmpWord = g.getVocabMetaData(tmpWord.Raw)
 
 		words = append(words, tmpWord)
@@ -76,7 +66,7 @@ func (g *Game) getVocabMetaData(rawWord string) Command_Word {
 		return word
 	}
 
-	refNum := getMotionVocabID(rawWord, g.Settings.OldStyle)
+	refNum := g.language().getMotionVocabID(rawWord, g.Settings.OldStyle)
 
 	if refNum != WORD_NOT_FOUND {
 		word.ID = refNum
@@ -84,7 +74,7 @@ func (g *Game) getVocabMetaData(rawWord string) Command_Word {
 		return word
 	}
 
-	refNum = getObjectVocabID(rawWord)
+	refNum = g.language().getObjectVocabID(rawWord)
 
 	if refNum != WORD_NOT_FOUND {
 		word.ID = refNum
@@ -92,7 +82,7 @@ func (g *Game) getVocabMetaData(rawWord string) Command_Word {
 		return word
 	}
 
-	refNum = getActionVocabID(rawWord, g.Settings.OldStyle)
+	refNum = g.language().getActionVocabID(rawWord, g.Settings.OldStyle)
 	if refNum != WORD_NOT_FOUND {
 		word.ID = refNum
 		word.WordType = ACTION
@@ -106,23 +96,15 @@ func (g *Game) getVocabMetaData(rawWord string) Command_Word {
 		return word
 	}
 
+	word.ID = WORD_NOT_FOUND
 	return word
 }
 
-func getMotionVocabID(rawWord string, oldStyle bool) int {
-
-	for i := 0; i < dungeon.NMOTIONS; i++ {
-		for j := 0; j < dungeon.Motions[i].Words.N; j++ {
-
-			if strnCaseCmpEqual(rawWord, dungeon.Motions[i].Words.Strs[j], TOKLEN) &&
-				(len(rawWord) > 1 || !strings.Contains(strings.ToUpper(rawWord), strings.ToUpper(dungeon.Ignore)) ||
-					!oldStyle) {
-				return i
-			}
-		}
+func (l *Locale) getMotionVocabID(rawWord string, oldStyle bool) int {
+	if oldStyle && ignoredLetter(rawWord) {
+		return WORD_NOT_FOUND
 	}
-
-	return WORD_NOT_FOUND
+	return l.vocabulary().LookupType(rawWord, MOTION)
 }
 
 /*
@@ -151,33 +133,21 @@ func getMotionVocabID(rawWord string, oldStyle bool) int {
 }
 */
 
-func getObjectVocabID(rawWord string) int {
-
-	for i := 0; i < dungeon.NOBJECTS+1; i++ {
-		for j := 0; j < dungeon.Objects[i].Words.N; j++ {
-
-			if strnCaseCmpEqual(rawWord, dungeon.Objects[i].Words.Strs[j], TOKLEN) {
-				return i
-			}
-		}
-	}
-
-	return WORD_NOT_FOUND
+func (l *Locale) getObjectVocabID(rawWord string) int {
+	return l.vocabulary().LookupType(rawWord, OBJECT)
 }
 
-func getActionVocabID(rawWord string, oldStyle bool) int {
-	for i := 0; i < dungeon.NACTIONS; i++ {
-		for j := 0; j < dungeon.Actions[i].Words.N; j++ {
-			if strnCaseCmpEqual(rawWord, dungeon.Actions[i].Words.Strs[j], TOKLEN) &&
-				(len(rawWord) > 1 || !strings.ContainsRune(dungeon.Ignore, rune(rawWord[0])) ||
-					!oldStyle) {
-
-				return i
-			}
-		}
+func (l *Locale) getActionVocabID(rawWord string, oldStyle bool) int {
+	if oldStyle && ignoredLetter(rawWord) {
+		return WORD_NOT_FOUND
 	}
+	return l.vocabulary().LookupType(rawWord, ACTION)
+}
 
-	return WORD_NOT_FOUND
+// ignoredLetter reports whether a word is one of the single letter
+// abbreviations the original game didn't know.
+func ignoredLetter(rawWord string) bool {
+	return len(rawWord) == 1 && strings.ContainsRune(dungeon.Ignore, rune(strings.ToUpper(rawWord)[0]))
 }
 
 /* Pre-processes a command input to see if we need to tease out a few specific
@@ -278,8 +248,52 @@ func (g *Game) preProcessCommand(command *Command) bool {
 	return false
 }
 
+// parseCommand rewrites a command as the player means it and tokenises it,
+// returning the rewritten command too. It reports false, having told the
+// player, when the first word isn't known or the command can't be
+// understood.
+func (g *Game) parseCommand(command string) (string, Command, bool) {
+	cmd := strings.ToUpper(command)
+
+	// Auto-correct swaps misspelt words for the nearest known word
+	if g.Settings.AutoCorrect && !g.Settings.OldStyle {
+		command = g.autoCorrect(command)
+		cmd = strings.ToUpper(command)
+	}
+
+	// "It" is the last object referred to, and ALL is parsed as the bare verb
+	all, except := false, []int(nil)
+	if g.pronounsEnabled() {
+		var ok bool
+		if command, ok = g.resolvePronouns(command); !ok {
+			return command, Command{}, false
+		}
+		if command, all, except, ok = g.parseAll(command); !ok {
+			return command, Command{}, false
+		}
+		cmd = strings.ToUpper(command)
+	}
+
+	tokCmd := g.tokeniseCommand(command)
+	tokCmd.All, tokCmd.Except = all, except
+	if len(tokCmd.Word) == 0 || tokCmd.Word[0].ID == WORD_NOT_FOUND {
+		// Oldstyle echoes the word as the original stored it
+		if g.Settings.OldStyle && len(tokCmd.Word) > 0 {
+			g.sspeak(dungeon.DONT_KNOW, tokCmd.Word[0].Raw)
+		} else if len(tokCmd.Word) > 0 {
+			g.dontKnow(cmd, tokCmd.Word[0].Raw)
+		} else {
+			g.sspeak(dungeon.DONT_KNOW, cmd)
+		}
+		return command, tokCmd, false
+	}
+
+	return command, tokCmd, true
+}
+
 func (g *Game) ProcessCommand(command string) error {
 	cmd := strings.ToUpper(command)
+	defer g.updateNotebook()
 
 	// Handle empty command - just redescribe location, don't count as a turn
 	if cmd == "" {
@@ -295,7 +309,7 @@ func (g *Game) ProcessCommand(command string) error {
 	// Game start condition - handle yes/no for instructions prompt
 	// This doesn't count as a regular turn
 	if g.Settings.NewGame && strings.Contains(cmd, "Y") {
-		g.Output = dungeon.Arbitrary_Messages[dungeon.CAVE_NEARBY]
+		g.Output = g.localise(dungeon.Arbitrary_Messages[dungeon.CAVE_NEARBY])
 		g.Novice = true
 		g.Limit = NOVICELIMIT
 		g.Settings.NewGame = false
@@ -303,7 +317,7 @@ func (g *Game) ProcessCommand(command string) error {
 		return nil
 
 	} else if g.Settings.NewGame && strings.Contains(cmd, "N") {
-		g.Output = dungeon.Arbitrary_Messages[dungeon.NO_MESSAGE]
+		g.Output = g.localise(dungeon.Arbitrary_Messages[dungeon.NO_MESSAGE])
 		g.Settings.NewGame = false
 		g.DescribeLocation()
 		g.ListObjects()
@@ -312,31 +326,51 @@ func (g *Game) ProcessCommand(command string) error {
 
 	} else if g.Settings.NewGame {
 		// Any other input during new game prompt - re-ask
-		g.Output = dungeon.Arbitrary_Messages[dungeon.WELCOME_YOU]
+		g.Output = g.localise(dungeon.Arbitrary_Messages[dungeon.WELCOME_YOU])
+
+		return nil
+	}
+
+	// Rewrite everyday English into commands the parser knows
+	if g.NormalisingEnabled() {
+		command = g.language().Normalise(command)
+		cmd = strings.ToUpper(command)
+	}
 
+	// UNDO rewinds earlier turns and doesn't count as a turn itself
+	if words := SplitWords(cmd); g.isUndoCommand(words) {
+		g.undo(words)
+		return nil
+	}
+
+	// MAP shows the explored cave and doesn't count as a turn
+	if cmd == "MAP" && !g.Settings.OldStyle {
+		g.ShowMap()
+		return nil
+	}
+
+	// EXITS lists the ways out and doesn't count as a turn
+	if cmd == "EXITS" && !g.Settings.OldStyle {
+		g.ShowExits()
 		return nil
 	}
 
 	// Tokenize command to check if it's valid before counting as a turn
-	tokCmd := g.tokeniseCommand(command)
-	if len(tokCmd.Word) == 0 || tokCmd.Word[0].ID == WORD_NOT_FOUND {
+	command, tokCmd, ok := g.parseCommand(command)
+	if !ok {
 		// Invalid command - don't count as a turn
-		g.sspeak(dungeon.DONT_KNOW, cmd)
-
 		return nil
 	}
+	cmd = strings.ToUpper(command)
+
+	// Remember the state before this turn so it can be undone
+	g.pushSnapshot(command)
 
 	// Valid command - now count as a turn and create tracing span
 	g.Turns++
 
-	// Use location context if available, otherwise use root context
-	parentCtx := g.Ctx
-	if g.LocationCtx != nil {
-		parentCtx = g.LocationCtx
-	}
-
 	// Start a span for this turn as child of location span
-	ctx, span := telemetry.StartSpan(parentCtx, "Turn: "+command)
+	ctx, span := telemetry.StartSpan(g.SpanContext(), "Turn: "+command)
 	defer span.End()
 
 	// Store context so events can be attached to this span
@@ -433,7 +467,7 @@ func (g *Game) ProcessCommand(command string) error {
 			tokCmd.CmdState = PROCESSING
 
 			if len(tokCmd.Word) > 0 && tokCmd.Word[0].ID == WORD_NOT_FOUND {
-				g.sspeak(dungeon.DONT_KNOW, tokCmd.Word[0].Raw)
+				g.dontKnow(tokCmd.Word[0].Raw, tokCmd.Word[0].Raw)
 				tokCmd.Word = []Command_Word{} // Clear the command
 				continue
 			}
@@ -490,7 +524,12 @@ func (g *Game) ProcessCommand(command string) error {
 			}
 
 			// Execute the action
-			phaseCode := g.action(&tokCmd)
+			var phaseCode PhaseCode
+			if tokCmd.All && tokCmd.Part == Intransitive {
+				phaseCode = g.actionAll(&tokCmd)
+			} else {
+				phaseCode = g.action(&tokCmd)
+			}
 
 			switch phaseCode {
 			case GO_TERMINATE:
@@ -546,6 +585,8 @@ func (g *Game) AskQuestion(query string, callback func(response string, game *Ga
 	g.QueryFlag = true
 	g.QueryResponse = ""
 
+	query = g.localise(query)
+
 	// Append query to existing output so previous messages aren't lost
 	if g.Output != "" {
 		g.Output = g.Output + "\n\n" + query
@@ -793,7 +834,11 @@ func (g *Game) action(command *Command) PhaseCode {
 		fallthrough
 
 	case Transitive:
-		// Analyse a transitive verb
+		// Analyse a transitive verb, remembering the object for "it".
+		// Oldobj is left alone: the bird hint reads it.
+		if command.Obj > NO_OBJECT && g.pronounsEnabled() {
+			g.Itobj = int32(command.Obj)
+		}
 		return g.handleTransitiveAction(command)
 	}
 
@@ -2130,7 +2175,7 @@ func (g *Game) suspend() PhaseCode {
 
 			// Ask for filename
 			game.AskQuestion(
-				"File name: ",
+				FILE_NAME_PROMPT,
 				func(filename string, game *Game) string {
 					filename = strings.TrimSpace(filename)
 					if filename == "" {
@@ -2140,12 +2185,12 @@ func (g *Game) suspend() PhaseCode {
 					// Save the game
 					err := game.SaveToFile(filename)
 					if err != nil {
-						game.Output = fmt.Sprintf("Failed to save game: %s\nTry again.", err.Error())
+						game.Output = fmt.Sprintf(game.localise(SAVE_FAILED), err.Error())
 						return game.Output
 					}
 
 					// Save successful - continue playing
-					game.Output = fmt.Sprintf("Game saved to %s.", filename)
+					game.Output = fmt.Sprintf(game.localise(SAVE_DONE), filename)
 					return game.Output
 				},
 			)
@@ -2173,7 +2218,7 @@ func (g *Game) resume() PhaseCode {
 
 				// Ask for filename
 				game.AskQuestion(
-					"File name: ",
+					FILE_NAME_PROMPT,
 					func(filename string, game *Game) string {
 						filename = strings.TrimSpace(filename)
 						if filename == "" {
@@ -2183,7 +2228,7 @@ func (g *Game) resume() PhaseCode {
 						// Load the game
 						err := game.LoadFromFile(filename)
 						if err != nil {
-							game.Output = fmt.Sprintf("Failed to load game: %s\nTry again.", err.Error())
+							game.Output = fmt.Sprintf(game.localise(RESUME_FAILED), err.Error())
 							return game.Output
 						}
 
@@ -2199,7 +2244,7 @@ func (g *Game) resume() PhaseCode {
 	} else {
 		// At start, just ask for filename
 		g.AskQuestion(
-			"File name: ",
+			FILE_NAME_PROMPT,
 			func(filename string, game *Game) string {
 				filename = strings.TrimSpace(filename)
 				if filename == "" {
@@ -2209,7 +2254,7 @@ func (g *Game) resume() PhaseCode {
 				// Load the game
 				err := game.LoadFromFile(filename)
 				if err != nil {
-					game.Output = fmt.Sprintf("Failed to load game: %s\nTry again.", err.Error())
+					game.Output = fmt.Sprintf(game.localise(RESUME_FAILED), err.Error())
 					return game.Output
 				}
 
@@ -340,8 +340,27 @@ func TestTokeniseCommand(t *testing.T) {
 
 	// Test invalid word
 	cmd = game.tokeniseCommand("xyznotaword")
-	if len(cmd.Word) > 0 && cmd.Word[0].ID != WORD_NOT_FOUND {
-		// Invalid words should be marked as not found
+	if len(cmd.Word) != 1 || cmd.Word[0].ID != WORD_NOT_FOUND {
+		t.Errorf("'xyznotaword' should be marked as not found, got %+v", cmd.Word)
+	}
+}
+
+// TestGetVocabMetaDataUnknownWord tests that an unknown word is told apart
+// from no word at all. Both used to come back as WORD_EMPTY, so the parser's
+// checks for WORD_NOT_FOUND never caught an unknown word.
+func TestGetVocabMetaDataUnknownWord(t *testing.T) {
+	game := newStartedGame()
+
+	if word := game.getVocabMetaData("FROBNITZ"); word.ID != WORD_NOT_FOUND || word.WordType != NO_WORD_TYPE {
+		t.Errorf("Expected an unknown word to be not found, got %+v", word)
+	}
+	if word := game.getVocabMetaData(""); word.ID != WORD_EMPTY || word.WordType != NO_WORD_TYPE {
+		t.Errorf("Expected no word to be empty, got %+v", word)
+	}
+
+	// An unknown second word isn't mistaken for a known one
+	if cmd := game.tokeniseCommand("get frobnitz"); cmd.Word[1].ID != WORD_NOT_FOUND {
+		t.Errorf("Expected FROBNITZ to be not found, got %+v", cmd.Word[1])
 	}
 }
 
@@ -1,61 +1,185 @@
 package advent
 
 import (
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/andrewsjg/goAdventure/dungeon"
 )
 
-// GetCompletions returns possible completions for a partial command.
-// It returns verbs, directions, and visible/carried objects that match the prefix.
-func (g *Game) GetCompletions(partial string) []string {
-	partial = strings.ToUpper(partial)
-	if partial == "" {
+// Completion is a word that completes the word being typed.
+type Completion struct {
+	Word     string   // The word, lower cased as the player would type it
+	WordType WordType // MOTION, OBJECT or ACTION
+	Line     string   // The whole input with the word completed
+}
+
+// carriedOnlyVerbs only make sense with an object the player is carrying.
+var carriedOnlyVerbs = map[int]bool{
+	dungeon.DROP:  true,
+	dungeon.THROW: true,
+	dungeon.POUR:  true,
+}
+
+// Complete returns the words that complete the last word of a partial
+// command, most likely first. The first word can be a verb, a direction or
+// an object that is here or carried. The second follows two word grammar:
+// after a verb only objects that are here or carried are offered, carried
+// ones alone for verbs like DROP and ones not yet carried first for GET;
+// after a direction nothing is. Oldstyle has no completions.
+func (g *Game) Complete(input string) []Completion {
+	if strings.TrimSpace(input) == "" || g.Settings.OldStyle {
 		return nil
 	}
 
+	words := strings.Fields(input)
+	partial := ""
+	if !strings.HasSuffix(input, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	stem := input[:len(input)-len(partial)]
+
+	var candidates []VocabEntry
+	switch len(words) {
+	case 0:
+		candidates = g.firstWordCandidates(partial)
+	case 1:
+		candidates = g.secondWordCandidates(words[0], partial)
+	}
+
 	seen := make(map[string]bool)
-	var completions []string
+	var completions []Completion
+	for _, entry := range candidates {
+		word := strings.ToLower(entry.Word)
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		completions = append(completions, Completion{Word: word, WordType: entry.WordType, Line: stem + word})
+	}
 
-	addCompletion := func(word string) {
-		word = strings.ToLower(word)
-		if word != "" && strings.HasPrefix(strings.ToUpper(word), partial) && !seen[word] {
-			seen[word] = true
-			completions = append(completions, word)
+	return completions
+}
+
+// firstWordCandidates returns verbs and directions, and objects here or
+// carried, starting with partial.
+func (g *Game) firstWordCandidates(partial string) []VocabEntry {
+	var candidates []VocabEntry
+	for _, entry := range g.language().vocabulary().WithPrefix(partial) {
+		if entry.WordType == OBJECT && !g.here(entry.ID) {
+			continue
 		}
+		candidates = append(candidates, entry)
+	}
+	return candidates
+}
+
+// secondWordCandidates returns the objects that can follow a verb, ranked by
+// how likely the player is to mean them.
+func (g *Game) secondWordCandidates(first string, partial string) []VocabEntry {
+	verb := g.getVocabMetaData(strings.ToUpper(first))
+	if verb.WordType != ACTION || dungeon.Actions[verb.ID].NoAction {
+		return nil
 	}
 
-	// Add action verbs (GET, DROP, LOOK, etc.)
-	for _, action := range dungeon.Actions {
-		for _, word := range action.Words.Strs {
-			if word != "" {
-				addCompletion(word)
-			}
+	var entries []VocabEntry
+	if partial == "" {
+		entries = g.language().vocabulary().Entries()
+	} else {
+		entries = g.language().vocabulary().WithPrefix(partial)
+	}
+
+	var candidates []VocabEntry
+	for _, entry := range entries {
+		if entry.WordType != OBJECT {
+			continue
+		}
+		if carriedOnlyVerbs[verb.ID] && !g.toting(entry.ID) {
+			continue
 		}
+		if !g.toting(entry.ID) && (g.dark() || !g.at(int32(entry.ID))) {
+			continue
+		}
+		candidates = append(candidates, entry)
 	}
 
-	// Add motion words (NORTH, SOUTH, UP, DOWN, etc.)
-	for _, motion := range dungeon.Motions {
-		for _, word := range motion.Words.Strs {
-			if word != "" {
-				addCompletion(word)
-			}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ri, rj := g.objectRank(verb.ID, candidates[i].ID), g.objectRank(verb.ID, candidates[j].ID)
+		if ri != rj {
+			return ri < rj
 		}
+		if candidates[i].ID != candidates[j].ID {
+			return candidates[i].ID < candidates[j].ID
+		}
+		return g.wordIndex(candidates[i]) < g.wordIndex(candidates[j])
+	})
+
+	// With nothing typed yet each object is offered once, by its main name
+	if partial == "" {
+		candidates = slices.CompactFunc(candidates, func(a, b VocabEntry) bool { return a.ID == b.ID })
 	}
 
-	// Add visible objects at current location
-	for i := 1; i <= dungeon.NOBJECTS; i++ {
-		if g.Objects[i].Place == g.Loc || g.Objects[i].Place == -1 {
-			// Object is here or being carried
-			for _, word := range dungeon.Objects[i].Words.Strs {
-				if word != "" {
-					addCompletion(word)
-				}
-			}
+	return candidates
+}
+
+// wordIndex returns the position of an object word among the object's names.
+func (g *Game) wordIndex(entry VocabEntry) int {
+	words := g.language().objectWords(entry.ID)
+	for i := 0; i < words.N && i < len(words.Strs); i++ {
+		if strings.EqualFold(words.Strs[i], entry.Word) {
+			return i
 		}
 	}
+	return words.N
+}
 
-	return completions
+// objectRank orders the objects offered after a verb, lowest first. Getting
+// favours portable objects not yet carried; other verbs favour what the
+// player is holding.
+func (g *Game) objectRank(verb int, obj int) int {
+	rank := 0
+	if verb == dungeon.CARRY {
+		if g.toting(obj) {
+			rank += 2
+		}
+		if g.Objects[obj].Fixed != IS_FREE {
+			rank++
+		}
+	} else if !g.toting(obj) {
+		rank++
+	}
+	return rank
+}
+
+// GetCompletions returns possible completions for a partial command as whole
+// lines of input. See Complete for the candidates offered.
+func (g *Game) GetCompletions(partial string) []string {
+	var lines []string
+	for _, c := range g.Complete(partial) {
+		lines = append(lines, c.Line)
+	}
+	return lines
+}
+
+// GetCorrections returns the input with its last word replaced by each of the
+// known words the player may have meant, for when there are no completions
+// because the word is misspelt. Oldstyle has no corrections.
+func (g *Game) GetCorrections(input string) []string {
+	words := strings.Fields(input)
+	if len(words) == 0 || g.Settings.OldStyle {
+		return nil
+	}
+
+	last := len(words) - 1
+	var corrections []string
+	for _, word := range g.Suggest(words[last], SUGGEST_LIMIT) {
+		words[last] = strings.ToLower(word)
+		corrections = append(corrections, strings.Join(words, " "))
+	}
+
+	return corrections
 }
 
 // GetAllVerbs returns all known action verbs for help display
@@ -63,9 +187,9 @@ func (g *Game) GetAllVerbs() []string {
 	seen := make(map[string]bool)
 	var verbs []string
 
-	for _, action := range dungeon.Actions {
-		if len(action.Words.Strs) > 0 && action.Words.Strs[0] != "" {
-			word := strings.ToLower(action.Words.Strs[0])
+	for i := range dungeon.Actions {
+		if words := g.language().actionWords(i); len(words.Strs) > 0 && words.Strs[0] != "" {
+			word := strings.ToLower(words.Strs[0])
 			if !seen[word] {
 				seen[word] = true
 				verbs = append(verbs, word)
@@ -76,8 +200,55 @@ func (g *Game) GetAllVerbs() []string {
 	return verbs
 }
 
-// GetAllDirections returns common movement directions
+// GetAllWords returns every word the game knows, motions, objects and
+// actions, lower cased and sorted.
+func (g *Game) GetAllWords() []string {
+	seen := make(map[string]bool)
+	var words []string
+
+	add := func(group dungeon.String_Group_t) {
+		for _, w := range group.Strs {
+			word := strings.ToLower(w)
+			if word != "" && !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	for _, groups := range g.language().words {
+		for _, group := range groups {
+			add(group)
+		}
+	}
+
+	sort.Strings(words)
+	return words
+}
+
+// GetObjectWords returns the words for every object, lower cased.
+func (g *Game) GetObjectWords() []string {
+	var words []string
+	for i := 1; i <= dungeon.NOBJECTS; i++ {
+		for _, w := range g.language().objectWords(i).Strs {
+			if w != "" {
+				words = append(words, strings.ToLower(w))
+			}
+		}
+	}
+	return words
+}
+
+// GetAllDirections returns the directions that lead out of the current
+// location, or the common compass directions when none are known
 func (g *Game) GetAllDirections() []string {
+	var directions []string
+	for _, exit := range g.Exits() {
+		directions = append(directions, strings.ToLower(exit.Word))
+	}
+	if len(directions) > 0 {
+		return directions
+	}
+
 	// Return the most common/useful directions
 	return []string{"north", "south", "east", "west", "up", "down", "in", "out"}
 }
@@ -91,8 +262,8 @@ func (g *Game) GetInteractableObjects() []string {
 	for i := 1; i <= dungeon.NOBJECTS; i++ {
 		if g.Objects[i].Place == g.Loc || g.Objects[i].Place == -1 {
 			// Object is here or being carried - get its primary name
-			if len(dungeon.Objects[i].Words.Strs) > 0 && dungeon.Objects[i].Words.Strs[0] != "" {
-				word := strings.ToLower(dungeon.Objects[i].Words.Strs[0])
+			if words := g.language().objectWords(i); len(words.Strs) > 0 && words.Strs[0] != "" {
+				word := strings.ToLower(words.Strs[0])
 				if !seen[word] {
 					seen[word] = true
 					objects = append(objects, word)
@@ -112,13 +283,13 @@ func (g *Game) GetLocationDescription() string {
 
 	// Check if dark
 	if g.dark() {
-		return dungeon.Arbitrary_Messages[dungeon.PITCH_DARK]
+		return g.localise(dungeon.Arbitrary_Messages[dungeon.PITCH_DARK])
 	}
 
 	// Get appropriate description (short or long based on visit count)
-	desc := dungeon.Locations[g.Loc].Description.Small
+	desc := g.localise(dungeon.Locations[g.Loc].Description.Small)
 	if desc == "" {
-		desc = dungeon.Locations[g.Loc].Description.Big
+		desc = g.localise(dungeon.Locations[g.Loc].Description.Big)
 	}
 
 	return desc
@@ -139,7 +310,7 @@ func (g *Game) GetVisibleObjects() []string {
 				prop = 0 // Default state for unfound objects
 			}
 			if int(prop) < len(dungeon.Objects[i].Descriptions) {
-				desc := dungeon.Objects[i].Descriptions[prop]
+				desc := g.localise(dungeon.Objects[i].Descriptions[prop])
 				if desc != "" {
 					objects = append(objects, desc)
 				}
@@ -149,6 +320,28 @@ func (g *Game) GetVisibleObjects() []string {
 	return objects
 }
 
+// TreasuresFound returns how many treasures the player has come across,
+// whether or not they have been brought back to the building.
+func (g *Game) TreasuresFound() int {
+	found := 0
+	for i := 1; i <= dungeon.NOBJECTS; i++ {
+		if dungeon.Objects[i].Is_Treasure && dungeon.Objects[i].Inventory != "" && !g.objectIsNotFound(i) {
+			found++
+		}
+	}
+	return found
+}
+
+// LocationsVisited returns how many different locations the player has been to.
+func (g *Game) LocationsVisited() int {
+	seen := map[int32]bool{int32(dungeon.LOC_START): true, g.Loc: true}
+	for _, e := range g.MapEdges {
+		seen[e.From] = true
+		seen[e.To] = true
+	}
+	return len(seen)
+}
+
 // IsDark returns true if the current location is dark
 func (g *Game) IsDark() bool {
 	return g.dark()
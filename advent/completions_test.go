@@ -1,6 +1,8 @@
 package advent
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/andrewsjg/goAdventure/dungeon"
@@ -115,6 +117,24 @@ func TestGetAllVerbs(t *testing.T) {
 	}
 }
 
+// TestGetAllWords tests that every word of each kind is listed once
+func TestGetAllWords(t *testing.T) {
+	game := &Game{}
+
+	words := game.GetAllWords()
+	if !slices.IsSorted(words) {
+		t.Error("GetAllWords should be sorted")
+	}
+	for _, expected := range []string{"north", "n", "xyzzy", "lamp", "keys", "get", "light", "yes"} {
+		if !slices.Contains(words, expected) {
+			t.Errorf("GetAllWords should include '%s'", expected)
+		}
+	}
+	if len(slices.Compact(slices.Clone(words))) != len(words) {
+		t.Error("GetAllWords should not repeat words")
+	}
+}
+
 // TestGetLocationDescription tests location description retrieval
 func TestGetLocationDescription(t *testing.T) {
 	game := NewGame(0, "", "", "", false, false, false, nil)
@@ -355,3 +375,123 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+// completionWords returns the words of a set of completions
+func completionWords(completions []Completion) []string {
+	var words []string
+	for _, c := range completions {
+		words = append(words, c.Word)
+	}
+	return words
+}
+
+// TestCompleteSecondWordObjects tests that only objects here or carried follow a verb
+func TestCompleteSecondWordObjects(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+	game.Objects[dungeon.KEYS].Place = CARRIED
+	game.Objects[dungeon.GRATE].Place = 999
+	game.Objects[dungeon.GRATE].Fixed = 999
+
+	completions := game.Complete("unlock ")
+	words := completionWords(completions)
+
+	if !slices.Contains(words, "lamp") || !slices.Contains(words, "keys") {
+		t.Errorf("Complete('unlock ') should offer lamp and keys, got %v", words)
+	}
+	if slices.Contains(words, "grate") {
+		t.Errorf("Complete('unlock ') should not offer the grate when it isn't here, got %v", words)
+	}
+	for _, c := range completions {
+		if c.WordType != OBJECT {
+			t.Errorf("Complete('unlock ') offered %s of type %d, want objects only", c.Word, c.WordType)
+		}
+		if !strings.HasPrefix(c.Line, "unlock ") {
+			t.Errorf("Completion line %q should keep the verb", c.Line)
+		}
+	}
+
+	if got := completionWords(game.Complete("unlock g")); slices.Contains(got, "get") || slices.Contains(got, "go") {
+		t.Errorf("Complete('unlock g') should not offer verbs, got %v", got)
+	}
+}
+
+// TestCompleteDropCarriedOnly tests that drop only offers carried objects
+func TestCompleteDropCarriedOnly(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+	game.Objects[dungeon.KEYS].Place = CARRIED
+
+	words := completionWords(game.Complete("drop "))
+
+	if !slices.Equal(words, []string{"keys"}) {
+		t.Errorf("Complete('drop ') = %v, want [keys]", words)
+	}
+}
+
+// TestCompleteGetRanksUncarried tests that get offers objects not yet carried first
+func TestCompleteGetRanksUncarried(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.KEYS].Place = CARRIED
+	game.Objects[dungeon.LAMP].Place = game.Loc
+
+	words := completionWords(game.Complete("get "))
+
+	if len(words) == 0 || words[len(words)-1] != "keys" {
+		t.Errorf("Complete('get ') should offer carried keys last, got %v", words)
+	}
+}
+
+// TestCompleteAfterMotion tests that nothing follows a motion word
+func TestCompleteAfterMotion(t *testing.T) {
+	game := newStartedGame()
+
+	if got := game.Complete("north "); len(got) != 0 {
+		t.Errorf("Complete('north ') should offer nothing, got %v", got)
+	}
+	if got := game.Complete("get lamp "); len(got) != 0 {
+		t.Errorf("Complete('get lamp ') should offer nothing, got %v", got)
+	}
+}
+
+// TestCompleteWordTypes tests that first word completions report their type
+func TestCompleteWordTypes(t *testing.T) {
+	game := newStartedGame()
+
+	for _, c := range game.Complete("no") {
+		if c.Word == "north" && c.WordType != MOTION {
+			t.Errorf("north should be a motion, got %d", c.WordType)
+		}
+	}
+}
+
+// TestTreasuresFound tests that treasures count once the player has seen them
+func TestTreasuresFound(t *testing.T) {
+	game := newStartedGame()
+
+	if got := game.TreasuresFound(); got != 0 {
+		t.Errorf("TreasuresFound at the start: got %d, want 0", got)
+	}
+
+	game.Objects[dungeon.NUGGET].Prop = STATE_FOUND
+	if got := game.TreasuresFound(); got != 1 {
+		t.Errorf("TreasuresFound after seeing the nugget: got %d, want 1", got)
+	}
+}
+
+// TestLocationsVisited tests that each location is counted once
+func TestLocationsVisited(t *testing.T) {
+	game := newStartedGame()
+
+	if got := game.LocationsVisited(); got != 1 {
+		t.Errorf("LocationsVisited at the start: got %d, want 1", got)
+	}
+
+	for _, cmd := range []string{"east", "west", "east"} {
+		game.ProcessCommand(cmd)
+		game.DoMove()
+	}
+	if got := game.LocationsVisited(); got != 2 {
+		t.Errorf("LocationsVisited after going in and out: got %d, want 2", got)
+	}
+}
@@ -0,0 +1,259 @@
+package advent
+
+import (
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+const (
+	EXITS_HEADER    = "Ways out of here:"
+	EXITS_NONE      = "There is no obvious way out of here."
+	EXITS_NONE_SEEN = "You haven't found a way out of here yet."
+	EXITS_UNKNOWN   = "somewhere you haven't been"
+	EXITS_SOMETIMES = " (sometimes)"
+)
+
+// magicMotions are the magic words. Finding them is part of the game, so
+// they are only listed once the player has used them.
+var magicMotions = map[int]bool{
+	dungeon.XYZZY:  true,
+	dungeon.PLUGH:  true,
+	dungeon.PLOVER: true,
+}
+
+// Exit is a motion that leads out of the current location.
+type Exit struct {
+	Motion      int32  // Index into dungeon.Motions
+	Word        string // The motion's name, e.g. NORTH
+	Destination int32  // Where the motion leads under current conditions
+	Name        string // Name of the destination if the player has been there
+	Sometimes   bool   // The way is only open some of the time, or leads elsewhere some of the time
+	Tried       bool   // The player has gone this way from here before
+}
+
+// Exits returns the motions that lead somewhere from the current location,
+// in the order the travel table lists them. Rules are evaluated against what
+// the player carries and the state of objects, without side effects; rules
+// that only apply by chance mark the exit Sometimes.
+//
+// When TriedExitsOnly is set, or it is too dark to see, only the exits the
+// player has already used are returned; magic words always need to have been
+// used. Forced locations have no exits as the player doesn't stay there.
+func (g *Game) Exits() []Exit {
+	if g.Loc <= 0 || int(g.Loc) >= len(dungeon.TKey) || dungeon.TKey[g.Loc] == 0 || forced(g.Loc) {
+		return nil
+	}
+
+	var exits []Exit
+	seen := make(map[int]bool)
+	triedOnly := g.Settings.TriedExitsOnly || g.dark()
+
+	for entry := int(dungeon.TKey[g.Loc]); ; entry++ {
+		motion := dungeon.Travel[entry].Motion
+		if !seen[motion] {
+			seen[motion] = true
+			if exit, ok := g.exitFor(motion); ok && (exit.Tried || (!triedOnly && !magicMotions[motion])) {
+				exits = append(exits, exit)
+			}
+		}
+		if dungeon.Travel[entry].Stop {
+			break
+		}
+	}
+
+	return exits
+}
+
+// exitFor evaluates the travel rules for a motion from the current location,
+// as PlayerMove does, and reports where it leads.
+func (g *Game) exitFor(motion int) (Exit, bool) {
+	entry := int(dungeon.TKey[g.Loc])
+	for dungeon.Travel[entry].Motion != motion {
+		if dungeon.Travel[entry].Stop {
+			return Exit{}, false
+		}
+		entry++
+	}
+
+	chance := false
+	chanceDest := int32(dungeon.LOC_NOWHERE)
+	dest := int32(dungeon.LOC_NOWHERE)
+
+	for {
+		t := dungeon.Travel[entry]
+
+		switch {
+		case t.CondType == dungeon.CondPct && t.CondArg1 > 0:
+			// Taken some of the time, otherwise the next rule applies
+			chance = true
+			if d, ok := g.travelDestination(t); ok {
+				chanceDest = d
+			}
+		case g.travelCondition(t):
+			if t.DestType == dungeon.DestSpecial && t.DestVal == 2 {
+				// Plover transport drops the emerald and carries on
+				break
+			}
+			if d, ok := g.travelDestination(t); ok {
+				dest = d
+			}
+			return g.newExit(motion, dest, chanceDest, chance)
+		}
+
+		// Skip to the next rule, as PlayerMove does on a failed condition
+		next := entry
+		for {
+			if dungeon.Travel[next].Stop {
+				return g.newExit(motion, dest, chanceDest, chance)
+			}
+			next++
+			if !traveleq(entry, next) {
+				break
+			}
+		}
+		entry = next
+	}
+}
+
+// travelCondition reports whether a travel rule's condition holds now.
+// Chance conditions are handled by the caller.
+func (g *Game) travelCondition(t dungeon.Travelop_t) bool {
+	switch t.CondType {
+	case dungeon.CondGoto, dungeon.CondPct:
+		return t.CondArg1 == 0
+	case dungeon.CondCarry:
+		return g.toting(t.CondArg1)
+	case dungeon.CondWith:
+		return g.toting(t.CondArg1) || g.at(int32(t.CondArg1))
+	case dungeon.CondNot:
+		return g.Objects[t.CondArg1].Prop != int32(t.CondArg2)
+	}
+	return false
+}
+
+// travelDestination returns where a travel rule leads, if anywhere.
+func (g *Game) travelDestination(t dungeon.Travelop_t) (int32, bool) {
+	switch t.DestType {
+	case dungeon.DestGoto:
+		return int32(t.DestVal), true
+	case dungeon.DestSpecial:
+		switch t.DestVal {
+		case 1:
+			// Plover-alcove passage, only with the emerald at most
+			if g.Holdng > 1 || (g.Holdng == 1 && !g.toting(dungeon.EMERALD)) {
+				return 0, false
+			}
+			if g.Loc == int32(dungeon.LOC_PLOVER) {
+				return int32(dungeon.LOC_ALCOVE), true
+			}
+			return int32(dungeon.LOC_PLOVER), true
+		case 3:
+			// Troll bridge, unless the troll is waiting to be paid
+			if g.Objects[dungeon.TROLL].Prop == dungeon.TROLL_PAIDONCE {
+				return 0, false
+			}
+			troll := dungeon.Objects[dungeon.TROLL]
+			return int32(troll.Plac + troll.Fixd - int(g.Loc)), true
+		}
+	}
+	return 0, false
+}
+
+// newExit builds the exit for a motion from the outcome of its rules.
+func (g *Game) newExit(motion int, dest int32, chanceDest int32, chance bool) (Exit, bool) {
+	leads := func(loc int32) bool { return loc > int32(dungeon.LOC_NOWHERE) && loc != g.Loc }
+
+	exit := Exit{Motion: int32(motion), Word: g.motionName(int32(motion)), Destination: dest}
+	switch {
+	case leads(dest):
+		exit.Sometimes = chance && chanceDest != dest
+	case leads(chanceDest):
+		exit.Destination = chanceDest
+		exit.Sometimes = true
+	default:
+		return Exit{}, false
+	}
+
+	if g.visited(exit.Destination) {
+		exit.Name = g.mapLabel(exit.Destination)
+	}
+	for _, e := range g.MapEdges {
+		if e.From == g.Loc && e.Motion == exit.Motion {
+			exit.Tried = true
+			break
+		}
+	}
+
+	return exit, true
+}
+
+// visited reports whether the player has been to a location.
+func (g *Game) visited(loc int32) bool {
+	if loc == g.Loc {
+		return true
+	}
+	for _, e := range g.MapEdges {
+		if e.From == loc || e.To == loc {
+			return true
+		}
+	}
+	return false
+}
+
+// ShowExits lists the ways out of the current location as game output,
+// grouping the motions that lead to the same place.
+func (g *Game) ShowExits() {
+	exits := g.Exits()
+
+	var msg string
+	switch {
+	case len(exits) == 0 && (g.Settings.TriedExitsOnly || g.dark()):
+		msg = g.localise(EXITS_NONE_SEEN)
+	case len(exits) == 0:
+		msg = g.localise(EXITS_NONE)
+	default:
+		lines := []string{g.localise(EXITS_HEADER)}
+		for _, group := range groupExits(exits) {
+			name := group[0].Name
+			if name == "" {
+				name = g.localise(EXITS_UNKNOWN)
+			}
+			if group[0].Sometimes {
+				name += g.localise(EXITS_SOMETIMES)
+			}
+
+			words := make([]string, 0, len(group))
+			for _, e := range group {
+				words = append(words, e.Word)
+			}
+			lines = append(lines, "  "+strings.Join(words, ", ")+": "+name)
+		}
+		msg = strings.Join(lines, "\n")
+	}
+
+	if g.Output != "" {
+		g.Output = g.Output + "\n\n" + msg
+	} else {
+		g.Output = msg
+	}
+}
+
+// groupExits gathers exits that lead to the same place in the same way,
+// keeping the order each place first appears in.
+func groupExits(exits []Exit) [][]Exit {
+	var groups [][]Exit
+	index := make(map[Exit]int)
+
+	for _, e := range exits {
+		key := Exit{Destination: e.Destination, Sometimes: e.Sometimes}
+		if i, ok := index[key]; ok {
+			groups[i] = append(groups[i], e)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, []Exit{e})
+	}
+
+	return groups
+}
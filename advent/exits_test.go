@@ -0,0 +1,169 @@
+package advent
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// exitTo returns the exit for a motion word, if there is one
+func exitTo(exits []Exit, word string) (Exit, bool) {
+	for _, e := range exits {
+		if e.Word == word || e.Motion == int32(english().vocabulary().LookupType(word, MOTION)) {
+			return e, true
+		}
+	}
+	return Exit{}, false
+}
+
+func TestExitsFromStart(t *testing.T) {
+	game := newStartedGame()
+
+	exits := game.Exits()
+
+	east, ok := exitTo(exits, "EAST")
+	if !ok || east.Destination != int32(dungeon.LOC_BUILDING) {
+		t.Fatalf("EAST should lead to the building, got %+v", exits)
+	}
+	if east.Name != "" || east.Tried {
+		t.Errorf("Building hasn't been visited, got %+v", east)
+	}
+	if west, ok := exitTo(exits, "WEST"); !ok || west.Destination != int32(dungeon.LOC_HILL) {
+		t.Errorf("WEST should lead to the hill, got %+v", exits)
+	}
+}
+
+func TestExitsNameVisitedPlaces(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessCommand("east")
+	game.DoMove()
+	game.ProcessCommand("west")
+	game.DoMove()
+
+	east, ok := exitTo(game.Exits(), "EAST")
+	if !ok || east.Name == "" || !east.Tried {
+		t.Errorf("EAST should be named and tried after visiting the building, got %+v", east)
+	}
+}
+
+func TestExitsHideMagicWords(t *testing.T) {
+	game := newStartedGame()
+	game.Loc = int32(dungeon.LOC_BUILDING)
+
+	if _, ok := exitTo(game.Exits(), "XYZZY"); ok {
+		t.Error("XYZZY should not be listed before it has been used")
+	}
+}
+
+func TestExitsFollowConditions(t *testing.T) {
+	game := newStartedGame()
+	game.Loc = int32(dungeon.LOC_GRATE)
+	game.Objects[dungeon.GRATE].Prop = dungeon.GRATE_CLOSED
+
+	if _, ok := exitTo(game.Exits(), "DOWN"); ok {
+		t.Error("DOWN should not be an exit while the grate is locked")
+	}
+
+	game.Objects[dungeon.GRATE].Prop = dungeon.GRATE_OPEN
+
+	down, ok := exitTo(game.Exits(), "DOWN")
+	if !ok || down.Destination != int32(dungeon.LOC_BELOWGRATE) {
+		t.Errorf("DOWN should lead below the grate once it is open, got %+v", game.Exits())
+	}
+}
+
+func TestExitsSometimes(t *testing.T) {
+	game := newStartedGame()
+	game.carry(int32(dungeon.LAMP), game.Loc)
+	game.Objects[dungeon.LAMP].Prop = dungeon.LAMP_BRIGHT
+
+	for loc := int32(1); loc <= dungeon.NLOCATIONS; loc++ {
+		game.Loc = loc
+		for _, e := range game.Exits() {
+			if e.Sometimes {
+				return
+			}
+		}
+	}
+	t.Error("Expected some exit in the dungeon to be open only by chance")
+}
+
+func TestExitsTriedOnly(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.TriedExitsOnly = true
+
+	if exits := game.Exits(); len(exits) != 0 {
+		t.Errorf("No exits have been tried yet, got %+v", exits)
+	}
+
+	game.ProcessCommand("east")
+	game.DoMove()
+	game.ProcessCommand("west")
+	game.DoMove()
+
+	exits := game.Exits()
+	if len(exits) != 1 || exits[0].Word != "EAST" {
+		t.Errorf("Only EAST has been tried, got %+v", exits)
+	}
+}
+
+func TestExitsCommand(t *testing.T) {
+	game := newStartedGame()
+	turns := game.Turns
+
+	game.ProcessCommand("exits")
+
+	if !strings.HasPrefix(game.Output, EXITS_HEADER) || !strings.Contains(game.Output, "EAST") {
+		t.Errorf("Expected a list of exits, got %q", game.Output)
+	}
+	if game.Turns != turns {
+		t.Errorf("EXITS should not take a turn, turns went from %d to %d", turns, game.Turns)
+	}
+
+	game.Settings.TriedExitsOnly = true
+	game.ProcessCommand("exits")
+
+	if game.Output != EXITS_NONE_SEEN {
+		t.Errorf("Expected %q, got %q", EXITS_NONE_SEEN, game.Output)
+	}
+}
+
+func TestGetAllDirectionsUsesExits(t *testing.T) {
+	game := newStartedGame()
+
+	directions := game.GetAllDirections()
+	if !slices.Contains(directions, "east") || !slices.Contains(directions, "downstream") {
+		t.Errorf("GetAllDirections should list the exits from the road, got %v", directions)
+	}
+	for _, truncated := range []string{"fores", "downs", "upwar", "d"} {
+		if slices.Contains(directions, truncated) {
+			t.Errorf("GetAllDirections should name each exit in full, got %q in %v", truncated, directions)
+		}
+	}
+}
+
+// TestMotionName tests that a motion is named by its compass word if it has
+// one, and otherwise by its first word in full
+func TestMotionName(t *testing.T) {
+	game := newStartedGame()
+
+	tests := map[int]string{
+		dungeon.EAST:    "EAST",
+		dungeon.DOWN:    "DOWN", // d, downward, down, descend
+		dungeon.UP:      "UP",
+		dungeon.OUTSIDE: "OUT",
+		dungeon.INSIDE:  "IN",
+		dungeon.MOT_6:   "FOREST",
+		dungeon.MOT_4:   "UPSTREAM",
+		dungeon.MOT_32:  "OUTDOORS",
+		dungeon.BACK:    "BACK",
+	}
+	for motion, want := range tests {
+		if got := game.motionName(int32(motion)); got != want {
+			t.Errorf("motionName(%d) = %q, want %q", motion, got, want)
+		}
+	}
+}
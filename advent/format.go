@@ -0,0 +1,204 @@
+package advent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// GAME_VERSION is what the VERSION command's %V shows.
+const GAME_VERSION = "(goAdventure)"
+
+// messageArgs lists the conversions taking an argument, in order, for each
+// arbitrary message that takes arguments. Every other message takes none.
+// ValidateMessages checks the dungeon text against it at startup and
+// TestMessageArgsMatchCallSites checks the calls to rspeak and sspeak.
+var messageArgs = map[int]string{
+	dungeon.DWARF_PACK:      "d",
+	dungeon.THROWN_KNIVES:   "d",
+	dungeon.MULTIPLE_HITS:   "d",
+	dungeon.DONT_KNOW:       "s",
+	dungeon.DO_WHAT:         "s",
+	dungeon.NO_SEE:          "s",
+	dungeon.WHAT_DO:         "s",
+	dungeon.OKEY_DOKEY:      "s",
+	dungeon.GARNERED_POINTS: "ddd",
+	dungeon.HINT_COST:       "d",
+	dungeon.TOTAL_SCORE:     "ddd",
+	dungeon.NEXT_HIGHER:     "d",
+	dungeon.VERSION_SKEW:    "dddd",
+}
+
+// actionArgs is messageArgs for the default messages of actions.
+var actionArgs = map[int]string{
+	dungeon.SEED:  "d",
+	dungeon.WASTE: "d",
+}
+
+// soundArgs is what object sounds may take: the bird sings the magic word.
+const soundArgs = "s"
+
+// formatMessage renders a dungeon message. It understands the original's
+// conversions: %d an integer, %s a string, %S an "s" when the last %d was
+// more than one, %V the game version and %% a percent sign. Anything that
+// can't be rendered is left as written and reported in the error, so a
+// mistake in a message shows up in the text rather than losing it.
+func formatMessage(msg string, args ...any) (string, error) {
+	var b strings.Builder
+	var errs []error
+	next := 0
+	plural := false
+
+	for i := 0; i < len(msg); i++ {
+		if msg[i] != '%' || i+1 == len(msg) {
+			b.WriteByte(msg[i])
+			continue
+		}
+
+		i++
+		verb := msg[i]
+		switch verb {
+		case '%':
+			b.WriteByte('%')
+		case 'S':
+			if plural {
+				b.WriteByte('s')
+			}
+		case 'V':
+			b.WriteString(GAME_VERSION)
+		case 'd', 's':
+			if next >= len(args) {
+				errs = append(errs, fmt.Errorf("%%%c: missing argument %d", verb, next+1))
+				b.WriteString(msg[i-1 : i+1])
+				continue
+			}
+			arg := args[next]
+			next++
+
+			if verb == 's' {
+				s, ok := arg.(string)
+				if !ok {
+					errs = append(errs, fmt.Errorf("%%s: argument %d is %T, not a string", next, arg))
+					b.WriteString(msg[i-1 : i+1])
+					continue
+				}
+				b.WriteString(s)
+				continue
+			}
+
+			n, ok := integerArg(arg)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%%d: argument %d is %T, not an integer", next, arg))
+				b.WriteString(msg[i-1 : i+1])
+				continue
+			}
+			plural = n > 1
+			fmt.Fprintf(&b, "%d", n)
+		default:
+			errs = append(errs, fmt.Errorf("unknown conversion %%%c", verb))
+			b.WriteString(msg[i-1 : i+1])
+		}
+	}
+
+	return b.String(), errors.Join(errs...)
+}
+
+func integerArg(arg any) (int64, bool) {
+	switch n := arg.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+// messageConversions returns the conversions in a message that take an
+// argument, in order, e.g. "dds".
+func messageConversions(msg string) string {
+	return conversionsOf(msg, "ds")
+}
+
+// conversionsOf returns the conversions in a message that are among verbs,
+// in order.
+func conversionsOf(msg string, verbs string) string {
+	var conversions []byte
+	for i := 0; i+1 < len(msg); i++ {
+		if msg[i] != '%' {
+			continue
+		}
+		i++
+		if strings.IndexByte(verbs, msg[i]) >= 0 {
+			conversions = append(conversions, msg[i])
+		}
+	}
+	return string(conversions)
+}
+
+// ValidateMessages checks that the game text, in the locale, takes the
+// arguments the engine speaks it with, and uses only the conversions
+// formatMessage knows. It is called at startup so a broken message or
+// locale is found before a player meets it.
+func (l *Locale) ValidateMessages() error {
+	var errs []error
+	check := func(subject string, msg string, want string) {
+		msg = l.localise(msg)
+		if got := messageConversions(msg); got != want {
+			errs = append(errs, fmt.Errorf("%s: takes %q, spoken with %q", subject, got, want))
+		}
+		for i := 0; i+1 < len(msg); i++ {
+			if msg[i] == '%' {
+				i++
+				if !strings.ContainsRune("dsSV%", rune(msg[i])) {
+					errs = append(errs, fmt.Errorf("%s: unknown conversion %%%c", subject, msg[i]))
+				}
+			}
+		}
+	}
+
+	for i, msg := range dungeon.Arbitrary_Messages {
+		check(fmt.Sprintf("message %d", i), msg, messageArgs[i])
+	}
+	for i, action := range dungeon.Actions {
+		check(fmt.Sprintf("action %d", i), action.Message, actionArgs[i])
+	}
+	for i, loc := range dungeon.Locations {
+		check(fmt.Sprintf("location %d", i), loc.Description.Small, "")
+		check(fmt.Sprintf("location %d", i), loc.Description.Big, "")
+	}
+	for i, obj := range dungeon.Objects {
+		subject := fmt.Sprintf("object %d", i)
+		check(subject, obj.Inventory, "")
+		for _, texts := range [][]string{obj.Descriptions, obj.Texts, obj.Changes} {
+			for _, msg := range texts {
+				check(subject, msg, "")
+			}
+		}
+		// Sounds are all spoken with the magic word, which most ignore
+		for _, msg := range obj.Sounds {
+			if c := messageConversions(l.localise(msg)); c != "" {
+				check(subject, msg, soundArgs)
+			}
+		}
+	}
+	for i, hint := range dungeon.Hints {
+		check(fmt.Sprintf("hint %d", i), hint.Question, "")
+		check(fmt.Sprintf("hint %d", i), hint.Hint, "")
+	}
+	for i, obituary := range dungeon.Obituaries {
+		check(fmt.Sprintf("obituary %d", i), obituary.Query, "")
+		check(fmt.Sprintf("obituary %d", i), obituary.Yes_Response, "")
+	}
+	for i, class := range dungeon.Classes {
+		check(fmt.Sprintf("class %d", i), class.Message, "")
+	}
+	for i, threshold := range dungeon.Turn_Thresholds {
+		check(fmt.Sprintf("turn threshold %d", i), threshold.Message, "")
+	}
+
+	return errors.Join(errs...)
+}
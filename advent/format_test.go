@@ -0,0 +1,198 @@
+package advent
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		args []any
+		want string
+	}{
+		{"Undone %d turn%S.", []any{1}, "Undone 1 turn."},
+		{"Undone %d turn%S.", []any{3}, "Undone 3 turns."},
+		{"You scored %d out of a possible %d, using %d turn%S.", []any{32, 430, int32(12)}, "You scored 32 out of a possible 430, using 12 turns."},
+		{"%s what?", []any{"GET"}, "GET what?"},
+		{"%s has %d", []any{"Lamp", 2}, "Lamp has 2"},
+		{"Open Adventure %V", nil, "Open Adventure " + GAME_VERSION},
+		{"100%% sure", nil, "100% sure"},
+		{"Nothing to fill in.", []any{"ignored"}, "Nothing to fill in."},
+	}
+
+	for _, tt := range tests {
+		got, err := formatMessage(tt.msg, tt.args...)
+		if err != nil {
+			t.Errorf("formatMessage(%q) returned error: %v", tt.msg, err)
+		}
+		if got != tt.want {
+			t.Errorf("formatMessage(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+// TestVspeakFillsStrings tests that a spoken message gets its string
+// arguments. vspeak used to look for the message in "%s" rather than "%s" in
+// the message, so they were never filled in.
+func TestVspeakFillsStrings(t *testing.T) {
+	game := newStartedGame()
+
+	got, err := game.vspeak(dungeon.Arbitrary_Messages[dungeon.DONT_KNOW], false, "FROBNITZ")
+	if err != nil || !strings.Contains(got, `"FROBNITZ"`) || strings.Contains(got, "%s") {
+		t.Errorf("Expected the word to be filled in, got %q, %v", got, err)
+	}
+
+	got, err = game.vspeak("%s what?", false, "GET")
+	if err != nil || got != "GET what?" {
+		t.Errorf("Expected \"GET what?\", got %q, %v", got, err)
+	}
+}
+
+func TestFormatMessageErrors(t *testing.T) {
+	tests := []struct {
+		msg  string
+		args []any
+		want string
+	}{
+		{"%d of them get you!", nil, "%d of them get you!"},
+		{"I see no %s here.", []any{3}, "I see no %s here."},
+		{"Odd %q here", nil, "Odd %q here"},
+	}
+
+	for _, tt := range tests {
+		got, err := formatMessage(tt.msg, tt.args...)
+		if err == nil {
+			t.Errorf("formatMessage(%q) should return an error", tt.msg)
+		}
+		if got != tt.want {
+			t.Errorf("formatMessage(%q) = %q, want the message left as written", tt.msg, got)
+		}
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	if err := english().ValidateMessages(); err != nil {
+		t.Errorf("The dungeon messages should be valid, got: %v", err)
+	}
+
+	if err := NewLocale(PseudoCatalog()).ValidateMessages(); err != nil {
+		t.Errorf("The pseudo-locale should be valid, got: %v", err)
+	}
+
+	// A locale that loses a placeholder is caught, even if not loaded from a file
+	lost := NewLocale(&Catalog{Messages: map[string]string{dungeon.Arbitrary_Messages[dungeon.NO_SEE]: "I see nothing."}})
+	if err := lost.ValidateMessages(); err == nil {
+		t.Error("Expected the lost placeholder to be reported")
+	}
+}
+
+func TestScoreTurnsPlural(t *testing.T) {
+	game := newStartedGame()
+	game.Turns = 1
+
+	game.score(ScoreGame)
+
+	if !strings.Contains(game.Output, "using 1 turn.") {
+		t.Errorf("Expected one turn in the score, got %q", game.Output)
+	}
+}
+
+// TestMessageArgsMatchCallSites checks every rspeak, tspeak and sspeak of an
+// arbitrary message passes as many arguments as messageArgs says it takes.
+func TestMessageArgsMatchCallSites(t *testing.T) {
+	values := messageConstants(t)
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	calls := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			fn, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (fn.Sel.Name != "rspeak" && fn.Sel.Name != "tspeak" && fn.Sel.Name != "sspeak") {
+				return true
+			}
+
+			name := dungeonConstant(call.Args[0])
+			msg, ok := values[name]
+			if !ok {
+				return true
+			}
+			calls++
+			if got, want := len(call.Args)-1, len(messageArgs[msg]); got != want {
+				t.Errorf("%s: %s(%s) passes %d argument(s), the message takes %d",
+					fset.Position(call.Pos()), fn.Sel.Name, name, got, want)
+			}
+			return true
+		})
+	}
+
+	if calls == 0 {
+		t.Error("Found no calls to check")
+	}
+}
+
+// dungeonConstant returns X for dungeon.X or int32(dungeon.X).
+func dungeonConstant(expr ast.Expr) string {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		expr = call.Args[0]
+	}
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		return dungeonConstant(paren.X)
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "dungeon" {
+			return sel.Sel.Name
+		}
+	}
+	return ""
+}
+
+// messageConstants reads the arbitrary message names, numbered as iota
+// numbers them, from the generated dungeon types.
+func messageConstants(t *testing.T) map[string]int {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "../dungeon/dungeonTypes.go", nil, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST || len(gen.Specs) == 0 || gen.Specs[0].(*ast.ValueSpec).Names[0].Name != "NO_MESSAGE" {
+			continue
+		}
+		values := make(map[string]int, len(gen.Specs))
+		for i, spec := range gen.Specs {
+			values[spec.(*ast.ValueSpec).Names[0].Name] = i
+		}
+		if values["CAVE_NEARBY"] != dungeon.CAVE_NEARBY {
+			t.Fatalf("Message constants are out of step with the dungeon")
+		}
+		return values
+	}
+
+	t.Fatal("No arbitrary message constants found")
+	return nil
+}
@@ -0,0 +1,115 @@
+package advent
+
+import (
+	"strings"
+)
+
+// commandSeparators are the words that separate commands on one input line.
+var commandSeparators = map[string]bool{
+	"THEN": true,
+	"AND":  true,
+}
+
+// SplitCommands splits an input line into the commands it contains. Commands
+// are separated by commas, periods, "then" and "and". A command that starts
+// with an object borrows the verb of the command before it, so "get lamp and
+// keys" becomes "GET LAMP" and "GET KEYS". Commands are normalised first, so
+// "pick up the lamp and keys" borrows its verb too.
+func (g *Game) SplitCommands(input string) []string {
+	var segments [][]string
+
+	for _, chunk := range strings.FieldsFunc(strings.ToUpper(input), func(r rune) bool {
+		return r == ',' || r == '.'
+	}) {
+		var segment []string
+		words := SplitWords(chunk)
+		for i, word := range words {
+			// "GET ALL EXCEPT LAMP AND KEYS" is one command
+			if commandSeparators[word] && i+1 < len(words) && exceptList(segment) &&
+				g.getVocabMetaData(words[i+1]).WordType == OBJECT {
+				segment = append(segment, word)
+				continue
+			}
+			if commandSeparators[word] {
+				if len(segment) > 0 {
+					segments = append(segments, segment)
+				}
+				segment = nil
+				continue
+			}
+			segment = append(segment, word)
+		}
+		if len(segment) > 0 {
+			segments = append(segments, segment)
+		}
+	}
+
+	if g.NormalisingEnabled() {
+		for i, segment := range segments {
+			segments[i] = SplitWords(g.language().Normalise(strings.Join(segment, " ")))
+		}
+	}
+
+	commands := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		if i > 0 {
+			prev := segments[i-1]
+			if len(prev) == 2 && g.getVocabMetaData(prev[0]).WordType == ACTION &&
+				g.getVocabMetaData(segment[0]).WordType == OBJECT {
+				segment = append([]string{prev[0]}, segment...)
+				segments[i] = segment
+			}
+		}
+		commands = append(commands, strings.Join(segment, " "))
+	}
+
+	return commands
+}
+
+// exceptList reports whether a command so far is "<verb> ALL EXCEPT ...".
+func exceptList(segment []string) bool {
+	return len(segment) >= 3 && allWords[segment[1]] && exceptWords[segment[2]]
+}
+
+// ProcessInput runs every command on an input line in turn. Moves made part
+// way through the line are carried out before the next command; a move made
+// by the last command is left to the caller as it is for ProcessCommand.
+//
+// The chain stops early when a question is waiting for an answer, the player
+// dies, the game ends, the player is carried on by a forced move or the
+// location becomes dark. Oldstyle takes one command per line, as the
+// original did.
+func (g *Game) ProcessInput(input string) error {
+	commands := g.SplitCommands(input)
+	if len(commands) <= 1 || g.Settings.OldStyle || g.Settings.NewGame {
+		return g.ProcessCommand(input)
+	}
+
+	var outputs []string
+	defer func() { g.Output = strings.Join(outputs, "\n\n") }()
+
+	for i, command := range commands {
+		numdie := g.Numdie
+		wasDark := g.dark()
+
+		err := g.ProcessCommand(command)
+
+		if err == nil && i < len(commands)-1 && g.Newloc != g.Loc && !g.QueryFlag && !g.GameOver {
+			g.DoMove()
+			g.DescribeLocation()
+			g.ListObjects()
+		}
+		if g.Output != "" {
+			outputs = append(outputs, g.Output)
+		}
+		if err != nil {
+			return err
+		}
+
+		if g.QueryFlag || g.GameOver || g.Numdie > numdie || g.LocForced() || (g.dark() && !wasDark) {
+			break
+		}
+	}
+
+	return nil
+}
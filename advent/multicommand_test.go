@@ -0,0 +1,95 @@
+package advent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestSplitCommands tests splitting input lines into commands
+func TestSplitCommands(t *testing.T) {
+	game := newStartedGame()
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"look", []string{"LOOK"}},
+		{"get lamp and keys, then go south", []string{"GET LAMP", "GET KEYS", "GO SOUTH"}},
+		{"east. west", []string{"EAST", "WEST"}},
+		{"get lamp, keys and food", []string{"GET LAMP", "GET KEYS", "GET FOOD"}},
+		{"north and south", []string{"NORTH", "SOUTH"}},
+		{"drop lamp then inventory", []string{"DROP LAMP", "INVENTORY"}},
+		{"then, and", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := game.SplitCommands(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCommands(%q): got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestProcessInputChain tests that each command runs with moves in between
+func TestProcessInputChain(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessInput("east then get lamp and keys, west")
+
+	if game.Turns != 4 {
+		t.Errorf("Turns: got %d, want 4", game.Turns)
+	}
+	if !game.toting(dungeon.LAMP) || !game.toting(dungeon.KEYS) {
+		t.Error("Expected lamp and keys to be carried")
+	}
+
+	// The last move is left for the caller, as with ProcessCommand
+	if game.Loc != int32(dungeon.LOC_BUILDING) || game.Newloc != int32(dungeon.LOC_START) {
+		t.Errorf("Expected to be leaving the building, got Loc %d Newloc %d", game.Loc, game.Newloc)
+	}
+}
+
+// TestProcessInputStopsInDark tests that the chain stops on entering darkness
+func TestProcessInputStopsInDark(t *testing.T) {
+	game := newStartedGame()
+
+	// The cobble crawl is lit, the debris room to its west isn't
+	game.Loc = int32(dungeon.LOC_COBBLE)
+	game.Newloc = game.Loc
+	turns := game.Turns
+
+	game.ProcessInput("west, west, east")
+
+	if !game.dark() {
+		t.Fatal("Expected to be in the dark")
+	}
+	if game.Turns != turns+1 {
+		t.Errorf("Chain should stop after entering the dark: got %d turns, want %d", game.Turns-turns, 1)
+	}
+}
+
+// TestProcessInputStopsOnQuestion tests that the chain stops for a question
+func TestProcessInputStopsOnQuestion(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessInput("quit, look")
+
+	if !game.QueryFlag {
+		t.Fatal("Expected a question to be pending")
+	}
+	if game.Turns != 1 {
+		t.Errorf("Turns: got %d, want 1", game.Turns)
+	}
+}
+
+// TestProcessInputOldStyle tests that oldstyle takes one command per line
+func TestProcessInputOldStyle(t *testing.T) {
+	game := newOldStyleGame()
+
+	game.ProcessInput("east then west")
+
+	if game.Turns != 0 {
+		t.Errorf("Oldstyle should reject the line, got %d turns", game.Turns)
+	}
+}
@@ -0,0 +1,226 @@
+package advent
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// normaliseFillers are words players add that the parser has no use for.
+var normaliseFillers = map[string]bool{
+	"THE":    true,
+	"A":      true,
+	"AN":     true,
+	"SOME":   true,
+	"MY":     true,
+	"THIS":   true,
+	"THAT":   true,
+	"OF":     true,
+	"PLEASE": true,
+}
+
+// normalisePhrases map multi-word and everyday verbs onto the vocabulary.
+// Two word phrases also match with an object between them, so "pick the
+// lamp up" is GET LAMP as well as "pick up the lamp". Longer phrases are
+// listed first.
+var normalisePhrases = []struct {
+	words []string
+	verb  string
+}{
+	{[]string{"CHECK", "INVENTORY"}, "INVENTORY"},
+	{[]string{"TAKE", "INVENTORY"}, "INVENTORY"},
+	{[]string{"PICK", "UP"}, "GET"},
+	{[]string{"PUT", "DOWN"}, "DROP"},
+	{[]string{"SET", "DOWN"}, "DROP"},
+	{[]string{"LOOK", "AT"}, "EXAMINE"},
+	{[]string{"LOOK", "AROUND"}, "LOOK"},
+	{[]string{"TURN", "ON"}, "LIGHT"},
+	{[]string{"SWITCH", "ON"}, "LIGHT"},
+	{[]string{"TURN", "OFF"}, "OFF"},
+	{[]string{"SWITCH", "OFF"}, "OFF"},
+	{[]string{"BLOW", "OUT"}, "OFF"},
+	{[]string{"PICK"}, "GET"},
+	{[]string{"GRAB"}, "GET"},
+	{[]string{"INSPECT"}, "EXAMINE"},
+}
+
+// normaliseMovers are verbs that only say the player wants to go somewhere,
+// besides the vocabulary's own synonyms for GO. Together with the prepositions
+// that can follow them they are dropped in front of a motion word, so "walk
+// north" is NORTH and "go into the building" is BUILDING.
+var (
+	normaliseMovers       = map[string]bool{"HEAD": true, "MOVE": true}
+	normalisePrepositions = map[string]bool{"INTO": true, "TO": true, "TOWARD": true, "TOWARDS": true, "THROUGH": true}
+)
+
+// normaliseExtraAdjectives describe objects in ways their inventory names
+// don't, as their location descriptions do.
+var normaliseExtraAdjectives = map[string][]int{
+	"SHINY":    {dungeon.LAMP},
+	"CHEERFUL": {dungeon.BIRD},
+}
+
+// inventoryAdjectives maps each adjective to the objects it can describe in
+// the locale. It is built from the inventory names, "Brass lantern", "Rare
+// coins" and so on: any word of a name that isn't in the vocabulary describes
+// that object.
+// Names starting with '*' are notes on objects that can't be carried, not
+// names, and the clam and oyster have their noises after a '>'.
+func (l *Locale) inventoryAdjectives() map[string][]int {
+	adjectives := make(map[string][]int)
+	for word, objs := range normaliseExtraAdjectives {
+		adjectives[word] = append(adjectives[word], objs...)
+	}
+
+	for obj := 1; obj <= dungeon.NOBJECTS; obj++ {
+		name := l.localise(dungeon.Objects[obj].Inventory)
+		if strings.HasPrefix(name, "*") {
+			continue
+		}
+		name, _, _ = strings.Cut(name, ">")
+
+		for _, word := range SplitWords(name) {
+			word = strings.ToUpper(word)
+			if len(word) < 3 || normaliseFillers[word] || commandSeparators[word] || len(l.vocabulary().Lookup(word)) > 0 {
+				continue
+			}
+			adjectives[word] = append(adjectives[word], obj)
+		}
+	}
+
+	return adjectives
+}
+
+// NormalisingEnabled reports whether commands are normalised before they are
+// parsed. Oldstyle never normalises as the original didn't.
+func (g *Game) NormalisingEnabled() bool {
+	return !g.Settings.NoNormalise && !g.Settings.OldStyle
+}
+
+// Normalise rewrites an everyday English command into the two word commands
+// the parser understands: "pick up the brass lantern" becomes GET LAMP. Words
+// it doesn't recognise are left for the parser to complain about, and a
+// command that needs no rewriting is returned unchanged.
+func (l *Locale) Normalise(command string) string {
+	words := SplitWords(strings.ToUpper(command))
+
+	normalised := make([]string, 0, len(words))
+	for _, word := range words {
+		if !normaliseFillers[word] {
+			normalised = append(normalised, word)
+		}
+	}
+
+	normalised = normaliseVerb(normalised)
+	normalised = l.normaliseMotion(normalised)
+	normalised = l.normaliseObjects(normalised)
+
+	if len(normalised) == 0 || slices.Equal(normalised, words) {
+		return command
+	}
+	return strings.Join(normalised, " ")
+}
+
+// normaliseVerb replaces a leading verb phrase with its vocabulary verb.
+func normaliseVerb(words []string) []string {
+	for _, p := range normalisePhrases {
+		if len(words) < len(p.words) {
+			continue
+		}
+
+		if slices.Equal(words[:len(p.words)], p.words) {
+			return append([]string{p.verb}, words[len(p.words):]...)
+		}
+
+		// Separated particle: PICK <object> UP
+		if len(p.words) == 2 && len(words) == 3 && words[0] == p.words[0] && words[2] == p.words[1] {
+			return []string{p.verb, words[1]}
+		}
+	}
+
+	return words
+}
+
+// normaliseMotion drops a verb of movement and its preposition in front of a
+// motion word.
+func (l *Locale) normaliseMotion(words []string) []string {
+	if len(words) < 2 || !l.isMover(words[0]) {
+		return words
+	}
+
+	rest := words[1:]
+	if len(rest) > 1 && normalisePrepositions[rest[0]] {
+		rest = rest[1:]
+	} else if words[0] == "GO" {
+		// The parser knows GO <motion>, and hints that GO isn't needed
+		return words
+	}
+	if l.vocabulary().LookupType(rest[0], MOTION) == WORD_NOT_FOUND {
+		return words
+	}
+	return rest
+}
+
+func (l *Locale) isMover(word string) bool {
+	if normaliseMovers[word] {
+		return true
+	}
+	v := l.vocabulary()
+	goVerb := v.LookupType("GO", ACTION)
+	return v.LookupType(word, ACTION) == goVerb && v.LookupType(word, MOTION) == WORD_NOT_FOUND
+}
+
+// normaliseObjects folds adjectives and second names into the object they
+// describe, so "brass lantern" is LAMP and "bars of silver" is SILVER. An
+// object named with adjectives is given its own name where the vocabulary
+// holds it in full.
+func (l *Locale) normaliseObjects(words []string) []string {
+	var result []string
+
+	for i := 0; i < len(words); i++ {
+		// A run of adjectives followed by names of one object
+		j := i
+		for j < len(words) && l.adjectives()[words[j]] != nil {
+			j++
+		}
+		obj := WORD_NOT_FOUND
+		k := j
+		for ; k < len(words); k++ {
+			o := l.vocabulary().LookupType(words[k], OBJECT)
+			if o == WORD_NOT_FOUND || (obj != WORD_NOT_FOUND && o != obj) {
+				break
+			}
+			obj = o
+		}
+
+		if obj == WORD_NOT_FOUND || k-i < 2 || !l.describesAll(words[i:j], obj) {
+			result = append(result, words[i])
+			continue
+		}
+
+		result = append(result, l.objectName(obj, words[k-1]))
+		i = k - 1
+	}
+
+	return result
+}
+
+// describesAll reports whether every adjective can describe obj.
+func (l *Locale) describesAll(adjectives []string, obj int) bool {
+	for _, word := range adjectives {
+		if !slices.Contains(l.adjectives()[word], obj) {
+			return false
+		}
+	}
+	return true
+}
+
+// objectName returns the name to use for an object: its first vocabulary
+// word, unless that is cut short, in which case the noun the player typed.
+func (l *Locale) objectName(obj int, typed string) string {
+	if words := l.objectWords(obj); words.N > 0 && len(words.Strs[0]) < TOKLEN {
+		return strings.ToUpper(words.Strs[0])
+	}
+	return typed
+}
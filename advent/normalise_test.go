@@ -0,0 +1,132 @@
+package advent
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		// Articles and filler
+		{"get the lamp", "GET LAMP"},
+		{"please drop a bottle", "DROP BOTTLE"},
+
+		// Multi-word verbs, together and split around the object
+		{"pick up the brass lantern", "GET LAMP"},
+		{"pick the lamp up", "GET LAMP"},
+		{"put down the keys", "DROP KEYS"},
+		{"put the keys down", "DROP KEYS"},
+		{"grab the food", "GET FOOD"},
+		{"look at the lamp", "EXAMINE LAMP"},
+		{"look around", "LOOK"},
+		{"turn on the lamp", "LIGHT LAMP"},
+		{"switch the lamp off", "OFF LAMP"},
+		{"take inventory", "INVENTORY"},
+
+		// Verbs of movement before a motion
+		{"walk north", "NORTH"},
+		{"go into the building", "BUILDING"},
+		{"head south", "SOUTH"},
+		{"run to the forest", "FOREST"},
+
+		// Adjectives and second names fold into the object
+		{"get the shiny brass lamp", "GET LAMP"},
+		{"drop the set of keys", "DROP KEYS"},
+		{"get the little bird", "GET BIRD"},
+		{"get the small bottle", "GET BOTTLE"},
+		{"get the bars of silver", "GET SILVER"},
+		{"drop the rare coins", "DROP COINS"},
+		{"get the gold nugget", "GET GOLD"},
+
+		// Left for the parser
+		{"get lamp", "get lamp"},
+		{"north", "north"},
+		{"the", "the"},
+		{"get the wicker lamp", "GET WICKER LAMP"}, // Adjective of another object
+		{"go to the keys", "GO TO KEYS"},           // Not a motion
+		{"go south", "go south"},
+		{"water plant", "water plant"},
+		{"frobnitz the lamp", "FROBNITZ LAMP"},
+	}
+
+	normalise := english().Normalise
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := normalise(tt.command); got != tt.want {
+				t.Errorf("Normalise(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+			// Normalising again changes nothing
+			if got := normalise(normalise(tt.command)); got != normalise(tt.command) {
+				t.Errorf("Normalise is not idempotent for %q: %q", tt.command, got)
+			}
+		})
+	}
+}
+
+func TestNormaliseFillersAreNotWords(t *testing.T) {
+	for word := range normaliseFillers {
+		if entries := english().vocabulary().Lookup(word); len(entries) > 0 {
+			t.Errorf("Filler %s is a vocabulary word: %v", word, entries)
+		}
+	}
+}
+
+func TestNormaliseAdjectives(t *testing.T) {
+	adjectives := english().adjectives()
+
+	if objs := adjectives["RARE"]; len(objs) != 2 {
+		t.Errorf("RARE should describe coins and spices, got %v", objs)
+	}
+	for _, word := range []string{"GRUNT", "MAZE"} {
+		if _, ok := adjectives[word]; ok {
+			t.Errorf("%s should not be an adjective", word)
+		}
+	}
+}
+
+func TestProcessCommandNormalises(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+
+	game.ProcessCommand("pick up the shiny brass lantern")
+
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Errorf("Expected to be carrying the lamp, output %q", game.Output)
+	}
+}
+
+func TestProcessCommandNoNormalise(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.NoNormalise = true
+	game.Objects[dungeon.LAMP].Place = game.Loc
+
+	game.ProcessCommand("pick up the brass lantern")
+
+	if game.Objects[dungeon.LAMP].Place == CARRIED {
+		t.Error("NoNormalise should not pick up the lamp")
+	}
+}
+
+func TestOldStyleDoesNotNormalise(t *testing.T) {
+	game := newOldStyleGame()
+
+	if game.NormalisingEnabled() {
+		t.Error("Oldstyle should not normalise commands")
+	}
+}
+
+func TestSplitCommandsNormalises(t *testing.T) {
+	game := newStartedGame()
+
+	got := game.SplitCommands("pick up the lamp and keys then walk west")
+	want := []string{"GET LAMP", "GET KEYS", "WEST"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitCommands = %v, want %v", got, want)
+	}
+}
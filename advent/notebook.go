@@ -0,0 +1,195 @@
+package advent
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// Notebook is what the player has learned about the cave, kept by the game
+// as the player comes across things rather than taken from what the player
+// says. It is for the AI player, whose conversation is too short to remember
+// a whole game, and is saved with the game so the AI can pick up where it
+// left off. The ways between locations are on the map (MapEdges).
+type Notebook struct {
+	Visited []int32         // Locations in the order they were first seen
+	Items   map[int32]int32 // Where each portable object was last seen, or CARRIED
+	Puzzles map[int32]int32 // Puzzles come across, by object: the turn each was solved, or 0
+}
+
+// notebookPuzzles are the obstacles the notebook keeps track of.
+var notebookPuzzles = []struct {
+	Object  int
+	Problem string // How the puzzle stands until it is solved
+	Solved  string // What the player did
+	IsDone  func(g *Game) bool
+}{
+	{dungeon.GRATE, "the grate is locked", "unlocked the grate", func(g *Game) bool { return g.Objects[dungeon.GRATE].Prop == dungeon.GRATE_OPEN }},
+	{dungeon.BIRD, "the bird hasn't been caught", "caught the bird", func(g *Game) bool { return g.Objects[dungeon.BIRD].Prop == dungeon.BIRD_CAGED }},
+	{dungeon.SNAKE, "the snake blocks the way", "drove off the snake", func(g *Game) bool { return g.Objects[dungeon.SNAKE].Prop == dungeon.SNAKE_CHASED }},
+	{dungeon.FISSURE, "there is no way across the fissure", "bridged the fissure", func(g *Game) bool { return g.Objects[dungeon.FISSURE].Prop == dungeon.BRIDGED }},
+	{dungeon.DOOR, "the door is rusted shut", "oiled the door", func(g *Game) bool { return g.Objects[dungeon.DOOR].Prop == dungeon.DOOR_UNRUSTED }},
+	{dungeon.PLANT, "the plant is too small to climb", "grew the plant", func(g *Game) bool { return g.Objects[dungeon.PLANT].Prop == dungeon.PLANT_GROWN }},
+	{dungeon.DRAGON, "the dragon guards the rug", "killed the dragon", func(g *Game) bool { return g.Objects[dungeon.DRAGON].Prop != dungeon.DRAGON_BARS }},
+	{dungeon.TROLL, "the troll wants paying to cross the bridge", "paid the troll", func(g *Game) bool { return g.Objects[dungeon.TROLL].Prop != dungeon.TROLL_UNPAID }},
+	{dungeon.BEAR, "the bear is hungry", "fed the bear", func(g *Game) bool { return g.Objects[dungeon.BEAR].Prop != dungeon.UNTAMED_BEAR }},
+}
+
+// clone returns a copy of the notebook that shares nothing with it.
+func (n Notebook) clone() Notebook {
+	return Notebook{
+		Visited: append([]int32(nil), n.Visited...),
+		Items:   maps.Clone(n.Items),
+		Puzzles: maps.Clone(n.Puzzles),
+	}
+}
+
+// updateNotebook notes what the player can see at their location and what
+// they are carrying, and any puzzles solved since last time.
+func (g *Game) updateNotebook() {
+	n := &g.Notebook
+	if n.Items == nil {
+		n.Items = map[int32]int32{}
+	}
+	if n.Puzzles == nil {
+		n.Puzzles = map[int32]int32{}
+	}
+	lit := !g.dark() && g.Loc > int32(dungeon.LOC_NOWHERE)
+
+	if lit && !containsLocation(n.Visited, g.Loc) {
+		n.Visited = append(n.Visited, g.Loc)
+	}
+
+	// What was here or carried may have moved on, so look again
+	for obj, loc := range n.Items {
+		if loc == CARRIED || (lit && loc == g.Loc) {
+			delete(n.Items, obj)
+		}
+	}
+	for i := 1; i <= dungeon.NOBJECTS; i++ {
+		if g.Objects[i].Fixed != IS_FREE || g.objectIsStashedOrUnseen(i) {
+			continue
+		}
+		switch {
+		case g.toting(i):
+			n.Items[int32(i)] = CARRIED
+		case lit && g.Objects[i].Place == g.Loc:
+			n.Items[int32(i)] = g.Loc
+		}
+	}
+
+	for _, p := range notebookPuzzles {
+		turn, met := n.Puzzles[int32(p.Object)]
+		if !met && lit && g.at(int32(p.Object)) {
+			met = true
+			n.Puzzles[int32(p.Object)] = 0
+		}
+		if met && turn == 0 && p.IsDone(g) {
+			n.Puzzles[int32(p.Object)] = max(g.Turns, 1)
+		}
+	}
+}
+
+// NotebookSummary describes what is in the notebook, for the AI player's
+// instructions. It is empty until the player has seen somewhere.
+func (g *Game) NotebookSummary() string {
+	n := g.Notebook
+	if len(n.Visited) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	places := make([]string, len(n.Visited))
+	for i, loc := range n.Visited {
+		places[i] = g.notebookLabel(loc)
+	}
+	fmt.Fprintf(&sb, "PLACES VISITED (%d): %s\n", len(places), strings.Join(places, "; "))
+
+	var ways []string
+	for _, from := range n.Visited {
+		var exits []string
+		for _, e := range g.MapEdges {
+			if e.From == from {
+				exits = append(exits, fmt.Sprintf("%s to %s", g.motionName(e.Motion), g.notebookLabel(e.To)))
+			}
+		}
+		if len(exits) > 0 {
+			ways = append(ways, fmt.Sprintf("  %s: %s", g.notebookLabel(from), strings.Join(exits, ", ")))
+		}
+	}
+	if len(ways) > 0 {
+		sb.WriteString("WAYS FOUND:\n" + strings.Join(ways, "\n") + "\n")
+	}
+
+	var carried, left []string
+	objs := make([]int32, 0, len(n.Items))
+	for obj := range n.Items {
+		objs = append(objs, obj)
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i] < objs[j] })
+	for _, obj := range objs {
+		name := strings.ToUpper(g.language().objectWords(int(obj)).Strs[0])
+		if loc := n.Items[obj]; loc == CARRIED {
+			carried = append(carried, name)
+		} else {
+			left = append(left, fmt.Sprintf("%s at %s", name, g.notebookLabel(loc)))
+		}
+	}
+	if len(carried) > 0 {
+		fmt.Fprintf(&sb, "ITEMS CARRIED: %s\n", strings.Join(carried, ", "))
+	}
+	if len(left) > 0 {
+		fmt.Fprintf(&sb, "ITEMS SEEN: %s\n", strings.Join(left, "; "))
+	}
+
+	var solved, unsolved []string
+	for _, p := range notebookPuzzles {
+		turn, met := n.Puzzles[int32(p.Object)]
+		switch {
+		case !met:
+		case turn > 0:
+			solved = append(solved, fmt.Sprintf("%s (turn %d)", p.Solved, turn))
+		default:
+			unsolved = append(unsolved, fmt.Sprintf("%s (at %s)", p.Problem, g.notebookLabel(g.puzzleLocation(p.Object))))
+		}
+	}
+	if len(solved) > 0 {
+		fmt.Fprintf(&sb, "PUZZLES SOLVED: %s\n", strings.Join(solved, "; "))
+	}
+	if len(unsolved) > 0 {
+		fmt.Fprintf(&sb, "PUZZLES UNSOLVED: %s\n", strings.Join(unsolved, "; "))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// puzzleLocation returns where a puzzle's object is, preferring the end of
+// a two-sided obstacle the player has been to.
+func (g *Game) puzzleLocation(obj int) int32 {
+	place, fixed := g.Objects[obj].Place, g.Objects[obj].Fixed
+	if fixed > 0 && !containsLocation(g.Notebook.Visited, place) {
+		return fixed
+	}
+	return place
+}
+
+// notebookLabel names a location, telling maze rooms apart by number.
+func (g *Game) notebookLabel(loc int32) string {
+	if mapNode(loc) != loc {
+		return fmt.Sprintf("%s room %d", g.mapLabel(mapNode(loc)), loc)
+	}
+	return g.mapLabel(loc)
+}
+
+func containsLocation(locs []int32, loc int32) bool {
+	for _, l := range locs {
+		if l == loc {
+			return true
+		}
+	}
+	return false
+}
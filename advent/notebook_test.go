@@ -0,0 +1,120 @@
+package advent
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// playNotebookCommands plays commands, making each move as the game loop does
+func playNotebookCommands(game *Game, commands ...string) {
+	for _, cmd := range commands {
+		game.ProcessCommand(cmd)
+		if game.Newloc != game.Loc {
+			game.DoMove()
+			game.DescribeLocation()
+			game.ListObjects()
+		}
+	}
+}
+
+// TestNotebookRecordsPlay tests that places and items are noted as the player finds them
+func TestNotebookRecordsPlay(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east", "get lamp", "west")
+
+	visited := game.Notebook.Visited
+	if len(visited) != 2 || visited[0] != int32(dungeon.LOC_START) || visited[1] != int32(dungeon.LOC_BUILDING) {
+		t.Errorf("Visited: got %v, want start then building", visited)
+	}
+	if loc := game.Notebook.Items[int32(dungeon.LAMP)]; loc != CARRIED {
+		t.Errorf("Lamp: got %d, want carried", loc)
+	}
+	if loc := game.Notebook.Items[int32(dungeon.KEYS)]; loc != int32(dungeon.LOC_BUILDING) {
+		t.Errorf("Keys: got %d, want the building", loc)
+	}
+
+	summary := game.NotebookSummary()
+	for _, want := range []string{"PLACES VISITED (2)", "EAST to Inside building", "ITEMS CARRIED: LAMP", "KEYS at Inside building"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+// TestNotebookEmptyAtStart tests that there is nothing to summarise before the game begins
+func TestNotebookEmptyAtStart(t *testing.T) {
+	game := newTestGame()
+
+	if summary := game.NotebookSummary(); summary != "" {
+		t.Errorf("Expected no summary before the game begins, got %q", summary)
+	}
+}
+
+// TestNotebookDroppedItem tests that an item is noted where it was left
+func TestNotebookDroppedItem(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east", "get lamp", "west", "drop lamp", "east")
+
+	if loc := game.Notebook.Items[int32(dungeon.LAMP)]; loc != int32(dungeon.LOC_START) {
+		t.Errorf("Lamp: got %d, want the start", loc)
+	}
+}
+
+// TestNotebookPuzzles tests that a puzzle is noted when seen and again when solved
+func TestNotebookPuzzles(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east", "get keys", "west", "south", "south", "south")
+
+	if !strings.Contains(game.NotebookSummary(), "PUZZLES UNSOLVED: the grate is locked") {
+		t.Errorf("Expected the locked grate to be unsolved:\n%s", game.NotebookSummary())
+	}
+
+	playNotebookCommands(game, "unlock grate")
+	if turn := game.Notebook.Puzzles[int32(dungeon.GRATE)]; turn != game.Turns {
+		t.Errorf("Grate: got solved on turn %d, want %d", turn, game.Turns)
+	}
+	if !strings.Contains(game.NotebookSummary(), "PUZZLES SOLVED: unlocked the grate") {
+		t.Errorf("Expected the grate to be solved:\n%s", game.NotebookSummary())
+	}
+
+	// Locking it again doesn't undo what was learned
+	playNotebookCommands(game, "lock grate")
+	if game.Notebook.Puzzles[int32(dungeon.GRATE)] == 0 {
+		t.Error("Expected the grate to stay solved")
+	}
+}
+
+// TestNotebookSavedWithGame tests that the notebook is restored with a saved game
+func TestNotebookSavedWithGame(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east", "get lamp")
+
+	saveFile := filepath.Join(t.TempDir(), "test.sav")
+	if err := game.SaveToFile(saveFile); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	restored := NewGame(0, "", "", "", false, false, false, nil)
+	if err := restored.LoadFromFile(saveFile); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if got, want := restored.NotebookSummary(), game.NotebookSummary(); got != want {
+		t.Errorf("Summary after restore:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestNotebookUndo tests that UNDO takes back what was noted
+func TestNotebookUndo(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east")
+	before := game.NotebookSummary()
+
+	playNotebookCommands(game, "get lamp", "undo")
+
+	if got := game.NotebookSummary(); got != before {
+		t.Errorf("Summary after undo:\n%s\nwant:\n%s", got, before)
+	}
+}
@@ -0,0 +1,102 @@
+package advent
+
+import (
+	"strings"
+	"testing"
+)
+
+// Helper to create an oldstyle game that's past the intro
+func newOldStyleGame() *Game {
+	game := NewGame(12345, "", "", "", false, true, false, nil)
+	game.ProcessCommand("no")
+	game.Output = ""
+	return &game
+}
+
+// TestOldStyleBanner tests that oldstyle starts with the original banner
+func TestOldStyleBanner(t *testing.T) {
+	game := NewGame(12345, "", "", "", false, true, false, nil)
+
+	if !strings.HasPrefix(game.Output, OLDSTYLE_BANNER+"\n") {
+		t.Errorf("Expected output to start with %q, got %q", OLDSTYLE_BANNER, game.Output)
+	}
+
+	modern := newTestGame()
+	if strings.Contains(modern.Output, OLDSTYLE_BANNER) {
+		t.Error("Banner should only be shown in oldstyle")
+	}
+}
+
+// TestOldStyleTruncatesTokens tests that unknown words are echoed as the
+// original stored them: upper case and cut to two sixbit words
+func TestOldStyleTruncatesTokens(t *testing.T) {
+	game := newOldStyleGame()
+	game.ProcessCommand("frobnicatorwidget")
+
+	if !strings.Contains(game.Output, `"FROBNICATO"`) {
+		t.Errorf("Expected truncated echo, got %q", game.Output)
+	}
+	if game.Turns != 0 {
+		t.Errorf("Unknown word should not use a turn, got %d", game.Turns)
+	}
+
+	modern := newStartedGame()
+	modern.ProcessCommand("frobnicatorwidget")
+	if !strings.Contains(modern.Output, `"FROBNICATORWIDGET"`) {
+		t.Errorf("Expected full echo outside oldstyle, got %q", modern.Output)
+	}
+}
+
+// TestOldStyleIgnoresLetters tests that the single letter abbreviations the
+// original didn't know are unknown in oldstyle
+func TestOldStyleIgnoresLetters(t *testing.T) {
+	for _, letter := range []string{"l", "x", "g", "z", "i"} {
+		game := newOldStyleGame()
+		if cmd := game.tokeniseCommand(letter); cmd.Word[0].ID != WORD_NOT_FOUND {
+			t.Errorf("%q should be unknown in oldstyle, got word type %d", letter, cmd.Word[0].WordType)
+		}
+
+		modern := newStartedGame()
+		if cmd := modern.tokeniseCommand(letter); cmd.Word[0].ID == WORD_NOT_FOUND {
+			t.Errorf("%q should be known outside oldstyle", letter)
+		}
+	}
+}
+
+// TestOldStyleNoModernCommands tests that UNDO, MAP and completions are off
+func TestOldStyleNoModernCommands(t *testing.T) {
+	game := newOldStyleGame()
+
+	game.ProcessCommand("east")
+	game.DoMove()
+	if game.UndoEnabled() || len(game.Snapshots) != 0 {
+		t.Error("Oldstyle should not keep undo snapshots")
+	}
+
+	for _, cmd := range []string{"undo", "map"} {
+		game.ProcessCommand(cmd)
+		if !strings.Contains(game.Output, "I don't know the word") {
+			t.Errorf("%q should be an unknown word in oldstyle, got %q", cmd, game.Output)
+		}
+	}
+
+	if completions := game.GetCompletions("ta"); completions != nil {
+		t.Errorf("Expected no completions in oldstyle, got %v", completions)
+	}
+}
+
+// TestOldStyleOriginalWording tests that oldstyle leaves the messages as the
+// original wrote them
+func TestOldStyleOriginalWording(t *testing.T) {
+	const msg = "There is a shiny brass lamp nearby on the floor."
+
+	game := newOldStyleGame()
+	if got, _ := game.vspeak(msg, false); got != msg {
+		t.Errorf("Expected the original wording outdoors, got %q", got)
+	}
+
+	modern := newStartedGame()
+	if got, _ := modern.vspeak(msg, false); !strings.Contains(got, "on the ground") {
+		t.Errorf("Expected the ground outdoors outside oldstyle, got %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package advent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+const (
+	PARSE_EMPTY        = "There is no command."
+	PARSE_UNKNOWN_WORD = "%s is not a word I know"
+	PARSE_OBJECTS_HERE = "; objects here are %s"
+	PARSE_NO_OBJECTS   = "; there are no objects here"
+)
+
+// ParseError explains why the game wouldn't understand a command.
+type ParseError struct {
+	Command string // The command as given
+	Word    string // The word that wasn't understood, if the trouble is one word
+	Message string // What the game would say, or what is wrong with the word
+}
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+// Parse tokenises a single command as ProcessCommand would, rewriting it as
+// the player means it, without playing it or changing the game, so it may be
+// called while another goroutine reads the game. UNDO, MAP and EXITS parse to
+// a command with no words. A command ProcessCommand wouldn't understand,
+// including one whose second word isn't known, gives a *ParseError.
+func (g *Game) Parse(command string) (Command, error) {
+	if strings.TrimSpace(command) == "" {
+		return Command{}, &ParseError{Command: command, Message: g.localise(PARSE_EMPTY)}
+	}
+
+	// Any answer will do for a question
+	if g.Settings.NewGame || g.QueryFlag {
+		return Command{}, nil
+	}
+
+	if g.NormalisingEnabled() {
+		command = g.language().Normalise(command)
+	}
+	cmd := strings.ToUpper(command)
+	if g.isUndoCommand(SplitWords(cmd)) || (!g.Settings.OldStyle && (cmd == "MAP" || cmd == "EXITS")) {
+		return Command{}, nil
+	}
+
+	if countWords(command) > 2 {
+		return Command{}, &ParseError{Command: command, Message: g.localise(dungeon.Arbitrary_Messages[dungeon.TWO_WORDS])}
+	}
+
+	// Parsing speaks its complaints. They are spoken to a copy of the game,
+	// to keep for the error, so the game itself is only read: the AI player
+	// validates on its own goroutine while the TUI shows the game's output.
+	parser := *g
+	parser.Output = ""
+
+	_, tokCmd, ok := parser.parseCommand(command)
+	switch {
+	case !ok && len(tokCmd.Word) > 0 && tokCmd.Word[0].ID == WORD_NOT_FOUND:
+		return Command{}, parser.unknownWord(command, tokCmd.Word[0].Raw, false)
+	case !ok:
+		return Command{}, &ParseError{Command: command, Message: strings.TrimSpace(parser.Output)}
+	case len(tokCmd.Word) > 1 && tokCmd.Word[1].ID == WORD_NOT_FOUND:
+		return Command{}, parser.unknownWord(command, tokCmd.Word[1].Raw, true)
+	}
+
+	return tokCmd, nil
+}
+
+// Validate reports whether ProcessCommand would understand a command, with a
+// *ParseError saying what is wrong if not.
+func (g *Game) Validate(command string) error {
+	_, err := g.Parse(command)
+	return err
+}
+
+// unknownWord explains that a word isn't known, offering, for the second
+// word, the objects the player could mean, and the nearest known word.
+func (g *Game) unknownWord(command, word string, second bool) *ParseError {
+	msg := fmt.Sprintf(g.localise(PARSE_UNKNOWN_WORD), word)
+	if second {
+		objects := g.GetInteractableObjects()
+		for i, obj := range objects {
+			objects[i] = strings.ToUpper(obj)
+		}
+		if len(objects) > 0 {
+			msg += fmt.Sprintf(g.localise(PARSE_OBJECTS_HERE), strings.Join(objects, ", "))
+		} else {
+			msg += g.localise(PARSE_NO_OBJECTS)
+		}
+	}
+	msg += "."
+
+	if words := g.Suggest(word, 1); len(words) > 0 {
+		msg += " " + fmt.Sprintf(g.localise(SUGGEST_DID_YOU_MEAN), words[0])
+	}
+
+	return &ParseError{Command: command, Word: word, Message: msg}
+}
@@ -0,0 +1,111 @@
+package advent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+func TestParse(t *testing.T) {
+	game := newStartedGame()
+
+	tests := []struct {
+		command  string
+		wordType WordType
+		id       int
+	}{
+		{"north", MOTION, dungeon.NORTH},
+		{"get lamp", ACTION, dungeon.CARRY},
+		{"lamp get", OBJECT, dungeon.LAMP}, // Swapped later by preProcessCommand
+		{"pick up the lamp", ACTION, dungeon.CARRY},
+	}
+
+	for _, tt := range tests {
+		cmd, err := game.Parse(tt.command)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.command, err)
+			continue
+		}
+		if len(cmd.Word) == 0 || cmd.Word[0].WordType != tt.wordType || cmd.Word[0].ID != tt.id {
+			t.Errorf("Parse(%q) = %+v, want first word type %d id %d", tt.command, cmd.Word, tt.wordType, tt.id)
+		}
+	}
+
+	for _, command := range []string{"UNDO", "MAP", "EXITS"} {
+		if err := game.Validate(command); err != nil {
+			t.Errorf("Validate(%q) returned error: %v", command, err)
+		}
+	}
+
+	// Any answer will do for a question
+	game.QueryFlag = true
+	if err := game.Validate("Y"); err != nil {
+		t.Errorf("Validate(\"Y\") returned error while asking a question: %v", err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	game := newStartedGame()
+	game.Loc, game.Newloc = int32(dungeon.LOC_BUILDING), int32(dungeon.LOC_BUILDING)
+	game.Output = "You are inside a building."
+	turns := game.Turns
+
+	tests := []struct {
+		command string
+		word    string
+		want    []string
+	}{
+		{"", "", []string{"no command"}},
+		{"frobnicate lamp", "FROBNICATE", []string{"FROBNICATE is not a word I know."}},
+		{"get shiny", "SHINY", []string{"SHINY is not a word I know; objects here are", "LAMP", "KEYS"}},
+		{"get lamp quickly now", "", []string{"2-word"}},
+		{"get it", "", []string{"what you mean by"}},
+	}
+
+	for _, tt := range tests {
+		err := game.Validate(tt.command)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Validate(%q) = %v, want a ParseError", tt.command, err)
+			continue
+		}
+		if parseErr.Word != tt.word {
+			t.Errorf("Validate(%q) blamed %q, want %q", tt.command, parseErr.Word, tt.word)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate(%q) = %q, want it to mention %q", tt.command, err.Error(), want)
+			}
+		}
+	}
+
+	if game.Output != "You are inside a building." || game.Turns != turns {
+		t.Errorf("Validate changed the game: output %q, turns %d", game.Output, game.Turns)
+	}
+}
+
+// TestValidateWhileShowingOutput tests that validating on one goroutine, as
+// the AI player does, doesn't race with showing the game on another. Run it
+// with -race.
+func TestValidateWhileShowingOutput(t *testing.T) {
+	game := newStartedGame()
+	output, outputType := game.Output, game.OutputType
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, command := range []string{"frobnicate", "get shiny", "get it", "get all except", "get lamp"} {
+			game.Validate(command)
+		}
+	}()
+
+	for range 100 {
+		if game.Output != output || game.OutputType != outputType {
+			t.Errorf("Validate changed the output to %q", game.Output)
+			break
+		}
+	}
+	<-done
+}
@@ -0,0 +1,39 @@
+package advent
+
+import "github.com/andrewsjg/goAdventure/dungeon"
+
+// Progress tallies what the player has achieved so far, so an AI player can
+// be rewarded for more than its score: comparing the tallies taken before
+// and after an action shows what the action did.
+type Progress struct {
+	Score              int
+	Locations          int      // Different locations visited
+	TreasuresSeen      int      // Treasures found, deposited or not
+	TreasuresDeposited int      // Treasures in the building
+	Puzzles            []string // What the player did to solve each puzzle solved
+	Deaths             int
+	Turns              int
+}
+
+// Progress returns the tally of what the player has achieved. Take it once
+// a move has been made, as things are only seen on arriving.
+func (g *Game) Progress() Progress {
+	p := Progress{
+		Score:         g.GetScore(),
+		Locations:     g.LocationsVisited(),
+		TreasuresSeen: g.TreasuresFound(),
+		Deaths:        int(g.Numdie),
+		Turns:         int(g.Turns),
+	}
+	for i := 1; i <= dungeon.NOBJECTS; i++ {
+		if dungeon.Objects[i].Is_Treasure && dungeon.Objects[i].Inventory != "" && g.Objects[i].Place == int32(dungeon.LOC_BUILDING) {
+			p.TreasuresDeposited++
+		}
+	}
+	for _, puzzle := range notebookPuzzles {
+		if puzzle.IsDone(g) {
+			p.Puzzles = append(p.Puzzles, puzzle.Solved)
+		}
+	}
+	return p
+}
@@ -0,0 +1,50 @@
+package advent
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestProgressAtStart tests that nothing has been achieved when the game begins
+func TestProgressAtStart(t *testing.T) {
+	p := newStartedGame().Progress()
+
+	if p.Locations != 1 || p.TreasuresSeen != 0 || p.TreasuresDeposited != 0 || p.Deaths != 0 || len(p.Puzzles) != 0 {
+		t.Errorf("Expected a fresh tally, got %+v", p)
+	}
+}
+
+// TestProgressCountsAchievements tests that places, puzzles and deposits are tallied
+func TestProgressCountsAchievements(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east", "get keys", "west", "south", "south", "south", "unlock grate")
+
+	p := game.Progress()
+	if p.Locations != 5 {
+		t.Errorf("Locations: got %d, want 5", p.Locations)
+	}
+	if !slices.Contains(p.Puzzles, "unlocked the grate") {
+		t.Errorf("Puzzles: got %v, want the grate unlocked", p.Puzzles)
+	}
+	if p.Turns != int(game.Turns) || p.Score != game.GetScore() {
+		t.Errorf("Got turns %d and score %d, want %d and %d", p.Turns, p.Score, game.Turns, game.GetScore())
+	}
+
+	// A treasure left in the building counts as deposited
+	game.Objects[dungeon.NUGGET].Place = int32(dungeon.LOC_BUILDING)
+	if got := game.Progress().TreasuresDeposited; got != 1 {
+		t.Errorf("Deposited: got %d, want 1", got)
+	}
+}
+
+// TestProgressCountsDeaths tests that a death is tallied even though the game goes on
+func TestProgressCountsDeaths(t *testing.T) {
+	game := newStartedGame()
+	game.croak()
+
+	if p := game.Progress(); p.Deaths != 1 || game.GameOver {
+		t.Errorf("Got %d deaths and game over %v, want 1 death and the game going on", p.Deaths, game.GameOver)
+	}
+}
@@ -0,0 +1,176 @@
+package advent
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+const (
+	PRONOUN_UNKNOWN  = "I don't know what you mean by \"%s\"."
+	ALL_NOTHING_HERE = "There is nothing here you can take."
+	ALL_UNSUPPORTED  = "You can only GET or DROP all."
+)
+
+// pronouns stand for the last object the player referred to.
+var pronouns = map[string]bool{
+	"IT":   true,
+	"THEM": true,
+}
+
+// allWords and exceptWords make up "GET ALL EXCEPT LAMP".
+var (
+	allWords    = map[string]bool{"ALL": true, "EVERYTHING": true}
+	exceptWords = map[string]bool{"EXCEPT": true, "BUT": true}
+)
+
+// pronounsEnabled reports whether "it" and "all" are understood. The
+// original parser knew neither.
+func (g *Game) pronounsEnabled() bool {
+	return !g.Settings.OldStyle
+}
+
+// resolvePronouns replaces "it" and "them" with the last object referred to.
+// It reports false, having told the player, when there is no such object.
+func (g *Game) resolvePronouns(command string) (string, bool) {
+	words := SplitWords(strings.ToUpper(command))
+	if !slices.ContainsFunc(words, func(w string) bool { return pronouns[w] }) {
+		return command, true
+	}
+
+	for i, word := range words {
+		if !pronouns[word] {
+			continue
+		}
+		if g.Itobj <= NO_OBJECT || g.Itobj > dungeon.NOBJECTS || g.language().objectWords(int(g.Itobj)).N == 0 {
+			g.speak(PRONOUN_UNKNOWN, word)
+			return command, false
+		}
+		words[i] = strings.ToUpper(g.language().objectWords(int(g.Itobj)).Strs[0])
+	}
+
+	return strings.Join(words, " "), true
+}
+
+// parseAll recognises "GET ALL" and "DROP ALL", optionally followed by
+// "EXCEPT" and the objects to leave alone. It returns the bare verb for the
+// parser, with all set, or the command unchanged. It reports false, having
+// told the player, when the command can't be understood.
+func (g *Game) parseAll(command string) (verb string, all bool, except []int, ok bool) {
+	words := SplitWords(strings.ToUpper(command))
+	if len(words) < 2 || !allWords[words[1]] {
+		return command, false, nil, true
+	}
+
+	// Unknown verbs are left for the parser to complain about
+	if g.getVocabMetaData(words[0]).ID == WORD_NOT_FOUND {
+		return command, false, nil, true
+	}
+	if id := g.language().vocabulary().LookupType(words[0], ACTION); id != dungeon.CARRY && id != dungeon.DROP {
+		g.speak(ALL_UNSUPPORTED)
+		return command, false, nil, false
+	}
+
+	rest := words[2:]
+	if len(rest) > 0 {
+		if !exceptWords[rest[0]] || len(rest) == 1 {
+			g.dontKnow(rest[0], rest[0])
+			return command, false, nil, false
+		}
+		for _, word := range rest[1:] {
+			if commandSeparators[word] {
+				continue
+			}
+			obj := g.language().vocabulary().LookupType(word, OBJECT)
+			if obj == WORD_NOT_FOUND {
+				g.dontKnow(word, word)
+				return command, false, nil, false
+			}
+			except = append(except, obj)
+			// ROD names both rods
+			if obj == dungeon.ROD {
+				except = append(except, dungeon.ROD2)
+			}
+		}
+	}
+
+	return words[0], true, except, true
+}
+
+// actionAll applies GET or DROP to each object here or carried in turn,
+// prefixing each reply with the object's name. Getting stops once the
+// player can carry no more.
+func (g *Game) actionAll(command *Command) PhaseCode {
+	var objs []int
+	for obj := 1; obj <= dungeon.NOBJECTS; obj++ {
+		if slices.Contains(command.Except, obj) || obj == dungeon.WATER || obj == dungeon.OIL {
+			continue
+		}
+		switch command.Verb {
+		case dungeon.CARRY:
+			if g.Objects[obj].Place == g.Loc && g.Objects[obj].Fixed == IS_FREE && !g.dark() {
+				objs = append(objs, obj)
+			}
+		case dungeon.DROP:
+			if g.toting(obj) {
+				objs = append(objs, obj)
+			}
+		}
+	}
+
+	if len(objs) == 0 {
+		if command.Verb == dungeon.DROP {
+			g.rspeak(int32(dungeon.NO_CARRY))
+		} else {
+			g.speak(ALL_NOTHING_HERE)
+		}
+		return GO_CLEAROBJ
+	}
+
+	for _, obj := range objs {
+		// The bird goes with its cage, so may have been dealt with already
+		if command.Verb == dungeon.CARRY && g.toting(obj) {
+			continue
+		}
+		if command.Verb == dungeon.DROP && !g.toting(obj) {
+			continue
+		}
+		if command.Verb == dungeon.CARRY && g.Holdng >= INVLIMIT {
+			g.rspeak(int32(dungeon.CARRY_LIMIT))
+			break
+		}
+
+		output := g.Output
+		g.Output = ""
+
+		var phase PhaseCode
+		if command.Verb == dungeon.CARRY {
+			phase = g.vcarry(command.Verb, obj)
+		} else {
+			phase = g.discard(command.Verb, obj)
+		}
+
+		reply := g.objectLabel(obj) + ": " + strings.TrimSpace(g.Output)
+		g.Output = output
+		if g.Output != "" {
+			g.Output += "\n"
+		}
+		g.Output += reply
+
+		g.Itobj = int32(obj)
+		if phase != GO_CLEAROBJ {
+			return phase
+		}
+	}
+
+	return GO_CLEAROBJ
+}
+
+// objectLabel returns an object's name as the inventory lists it.
+func (g *Game) objectLabel(obj int) string {
+	if name := strings.TrimPrefix(g.localise(dungeon.Objects[obj].Inventory), "*"); name != "" {
+		return name
+	}
+	return strings.ToUpper(g.language().objectWords(obj).Strs[0])
+}
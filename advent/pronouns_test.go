@@ -0,0 +1,202 @@
+package advent
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+func TestGetIt(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+	game.Objects[dungeon.LAMP].Prop = 0
+
+	game.ProcessCommand("get lamp")
+	game.ProcessCommand("drop it")
+
+	if game.Objects[dungeon.LAMP].Place != game.Loc {
+		t.Errorf("'drop it' should drop the lamp, got place=%d", game.Objects[dungeon.LAMP].Place)
+	}
+
+	game.ProcessCommand("get it")
+
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Errorf("'get it' should get the lamp, got place=%d", game.Objects[dungeon.LAMP].Place)
+	}
+}
+
+func TestItLeavesBirdHintAlone(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.BIRD].Place = game.Loc
+	game.Objects[dungeon.BIRD].Prop = 0
+
+	game.ProcessCommand("get bird")
+
+	if game.Itobj != int32(dungeon.BIRD) {
+		t.Errorf("IT should stand for the bird, got %d", game.Itobj)
+	}
+	if game.Oldobj != NO_OBJECT {
+		t.Errorf("Oldobj, read by the bird hint, should be left alone, got %d", game.Oldobj)
+	}
+}
+
+func TestItWithoutObject(t *testing.T) {
+	game := newStartedGame()
+	turns := game.Turns
+
+	game.ProcessCommand("get it")
+
+	if !strings.Contains(game.Output, `"IT"`) {
+		t.Errorf("Expected a message about IT, got %q", game.Output)
+	}
+	if game.Turns != turns {
+		t.Errorf("Unresolved IT should not take a turn, turns went from %d to %d", turns, game.Turns)
+	}
+}
+
+// TestItOutOfRange tests that an "it" object outside the dungeon is treated
+// as no object rather than indexing past the vocabulary
+func TestItOutOfRange(t *testing.T) {
+	game := newStartedGame()
+	game.Itobj = dungeon.NOBJECTS + 1
+
+	game.ProcessCommand("get it")
+
+	if !strings.Contains(game.Output, `"IT"`) {
+		t.Errorf("Expected a message about IT, got %q", game.Output)
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	game := newStartedGame()
+	for _, obj := range []int{dungeon.LAMP, dungeon.KEYS, dungeon.FOOD} {
+		game.Objects[obj].Place = game.Loc
+		game.Objects[obj].Prop = 0
+	}
+	turns := game.Turns
+
+	game.ProcessCommand("get all")
+
+	for _, obj := range []int{dungeon.LAMP, dungeon.KEYS, dungeon.FOOD} {
+		if game.Objects[obj].Place != CARRIED {
+			t.Errorf("Object %d should be carried after 'get all', got place=%d", obj, game.Objects[obj].Place)
+		}
+	}
+	for _, name := range []string{"Brass lantern: OK", "Set of keys: OK", "Tasty food: OK"} {
+		if !strings.Contains(game.Output, name) {
+			t.Errorf("Expected %q in output, got %q", name, game.Output)
+		}
+	}
+	if game.Turns != turns+1 {
+		t.Errorf("'get all' should take one turn, took %d", game.Turns-turns)
+	}
+}
+
+func TestGetAllExcept(t *testing.T) {
+	game := newStartedGame()
+	for _, obj := range []int{dungeon.LAMP, dungeon.KEYS, dungeon.FOOD} {
+		game.Objects[obj].Place = game.Loc
+		game.Objects[obj].Prop = 0
+	}
+
+	game.ProcessInput("get all except keys and food")
+
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Errorf("Lamp should be carried, got place=%d", game.Objects[dungeon.LAMP].Place)
+	}
+	for _, obj := range []int{dungeon.KEYS, dungeon.FOOD} {
+		if game.Objects[obj].Place != game.Loc {
+			t.Errorf("Object %d should be left behind, got place=%d", obj, game.Objects[obj].Place)
+		}
+	}
+}
+
+func TestGetAllRespectsInventoryLimit(t *testing.T) {
+	game := newStartedGame()
+	for _, obj := range []int{dungeon.LAMP, dungeon.KEYS} {
+		game.Objects[obj].Place = game.Loc
+		game.Objects[obj].Prop = 0
+	}
+	game.Holdng = INVLIMIT - 1
+
+	game.ProcessCommand("get all")
+
+	carried := 0
+	for _, obj := range []int{dungeon.LAMP, dungeon.KEYS} {
+		if game.Objects[obj].Place == CARRIED {
+			carried++
+		}
+	}
+	if carried != 1 {
+		t.Errorf("Expected one object carried at the limit, got %d", carried)
+	}
+	if !strings.Contains(game.Output, dungeon.Arbitrary_Messages[dungeon.CARRY_LIMIT]) {
+		t.Errorf("Expected carry limit message, got %q", game.Output)
+	}
+}
+
+func TestGetAllNothingHere(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessCommand("get all")
+
+	if game.Output != ALL_NOTHING_HERE {
+		t.Errorf("Expected %q, got %q", ALL_NOTHING_HERE, game.Output)
+	}
+}
+
+func TestDropAll(t *testing.T) {
+	game := newStartedGame()
+	game.carry(int32(dungeon.LAMP), game.Loc)
+	game.carry(int32(dungeon.KEYS), game.Loc)
+
+	game.ProcessCommand("drop all but lamp")
+
+	if game.Objects[dungeon.KEYS].Place != game.Loc {
+		t.Errorf("Keys should be dropped, got place=%d", game.Objects[dungeon.KEYS].Place)
+	}
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Errorf("Lamp should still be carried, got place=%d", game.Objects[dungeon.LAMP].Place)
+	}
+
+	game.ProcessCommand("drop all")
+	game.ProcessCommand("drop all")
+
+	if game.Output != dungeon.Arbitrary_Messages[dungeon.NO_CARRY] {
+		t.Errorf("Expected not carrying message, got %q", game.Output)
+	}
+}
+
+func TestAllOnlyForGetAndDrop(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessCommand("light all")
+
+	if game.Output != ALL_UNSUPPORTED {
+		t.Errorf("Expected %q, got %q", ALL_UNSUPPORTED, game.Output)
+	}
+}
+
+func TestOldStyleHasNoPronouns(t *testing.T) {
+	game := newOldStyleGame()
+	game.ProcessCommand("no")
+
+	game.ProcessCommand("get all")
+
+	if strings.Contains(game.Output, ALL_NOTHING_HERE) || strings.Contains(game.Output, ": OK") {
+		t.Errorf("Oldstyle should not understand ALL, got %q", game.Output)
+	}
+}
+
+func TestSplitCommandsKeepsExceptList(t *testing.T) {
+	game := newStartedGame()
+
+	got := game.SplitCommands("get all except lamp and keys then go north")
+	want := []string{"GET ALL EXCEPT LAMP AND KEYS", "GO NORTH"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitCommands = %q, want %q", got, want)
+	}
+}
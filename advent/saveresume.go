@@ -8,6 +8,13 @@ import (
 	"github.com/andrewsjg/goAdventure/dungeon"
 )
 
+const (
+	FILE_NAME_PROMPT = "File name: "
+	SAVE_DONE        = "Game saved to %s."
+	SAVE_FAILED      = "Failed to save game: %s\nTry again."
+	RESUME_FAILED    = "Failed to load game: %s\nTry again."
+)
+
 // SaveGame represents the complete game state for serialization
 type SaveGame struct {
 	Magic   string `json:"magic"`   // Magic string to identify save files
@@ -77,15 +84,17 @@ func (g *Game) LoadFromFile(filename string) error {
 	}
 
 	// Restore the game state
-	// Preserve settings and callback from current game
+	// Preserve settings, callback and locale from current game
 	settings := g.Settings
 	callback := g.OnQueryResponse
+	locale := g.locale
 
 	*g = saveData.Game
 
 	// Restore the preserved fields
 	g.Settings = settings
 	g.OnQueryResponse = callback
+	g.locale = locale
 
 	if g.Settings.EnableDebug {
 		fmt.Printf("DEBUG: Game loaded from %s\n", filename)
@@ -132,6 +141,11 @@ func isValidGameState(g *Game) bool {
 		}
 	}
 
+	// Bounds check for the object "it" refers to
+	if g.Itobj < NO_OBJECT || g.Itobj > dungeon.NOBJECTS {
+		return false
+	}
+
 	// Bounds check for dwarf counts
 	if g.Dtotal < 0 || g.Dtotal > dungeon.NDWARVES ||
 		g.Dkill < 0 || g.Dkill > dungeon.NDWARVES {
@@ -178,6 +192,34 @@ func isValidGameState(g *Game) bool {
 		}
 	}
 
+	// Bounds check for the explored map
+	for _, e := range g.MapEdges {
+		if e.From < 0 || e.From > dungeon.NLOCATIONS ||
+			e.To < 0 || e.To > dungeon.NLOCATIONS ||
+			e.Motion < 0 || e.Motion >= dungeon.NMOTIONS {
+			return false
+		}
+	}
+
+	// Bounds check for the notebook
+	validLoc := func(loc int32) bool { return loc > 0 && loc <= dungeon.NLOCATIONS }
+	validObj := func(obj int32) bool { return obj > 0 && obj <= dungeon.NOBJECTS }
+	for _, loc := range g.Notebook.Visited {
+		if !validLoc(loc) {
+			return false
+		}
+	}
+	for obj, loc := range g.Notebook.Items {
+		if !validObj(obj) || (loc != CARRIED && !validLoc(loc)) {
+			return false
+		}
+	}
+	for obj, turn := range g.Notebook.Puzzles {
+		if !validObj(obj) || turn < 0 {
+			return false
+		}
+	}
+
 	return true
 }
 
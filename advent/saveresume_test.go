@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
 )
 
 // TestSaveAndLoad tests saving and loading a game
@@ -45,6 +47,23 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+// TestLoadInvalidItObject tests that a save whose "it" object is outside the
+// dungeon is rejected
+func TestLoadInvalidItObject(t *testing.T) {
+	game := NewGame(12345, "", "", "", false, false, false, nil)
+	game.Itobj = dungeon.NOBJECTS + 1
+
+	saveFile := filepath.Join(t.TempDir(), "test.sav")
+	if err := game.SaveToFile(saveFile); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	game2 := NewGame(0, "", "", "", false, false, false, nil)
+	if err := game2.LoadFromFile(saveFile); err == nil {
+		t.Error("LoadFromFile should fail with an out of range IT object")
+	}
+}
+
 // TestLoadInvalidMagic tests loading a file with wrong magic number
 func TestLoadInvalidMagic(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -94,9 +113,7 @@ func TestAutoSaveRotation(t *testing.T) {
 	tmpDir := t.TempDir()
 	saveFile := filepath.Join(tmpDir, "autosave.sav")
 
-	game := NewGame(12345, "", "", "", false, false, true, nil)
-	game.Settings.Autosave = true
-	game.Settings.AutoSaveFileName = saveFile
+	game := NewGame(12345, "", saveFile, "", false, false, true, nil)
 
 	// Do 5 autosaves
 	for i := 0; i < 5; i++ {
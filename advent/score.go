@@ -27,7 +27,8 @@ func (g *Game) terminate(mode Termination) {
 		g.rspeak((int32(dungeon.WITHOUT_SUSPENDS)))
 	}
 
-	g.rspeak(int32(dungeon.TOTAL_SCORE), points, mxscr, g.Turns, g.Turns)
+	g.rspeak(int32(dungeon.TOTAL_SCORE), points, mxscr, g.Turns)
+	g.undoSummary()
 
 	for i := 1; i < dungeon.NCLASSES; i++ {
 		if dungeon.Classes[i].Threshold >= points {
@@ -35,7 +36,7 @@ func (g *Game) terminate(mode Termination) {
 
 			if i < dungeon.NCLASSES {
 				nxt := dungeon.Classes[i].Threshold + 1 - points
-				g.rspeak(int32(dungeon.NEXT_HIGHER), nxt, nxt)
+				g.rspeak(int32(dungeon.NEXT_HIGHER), nxt)
 			} else {
 				g.rspeak(int32(dungeon.NO_HIGHER))
 			}
@@ -59,7 +60,8 @@ func (g *Game) score(mode Termination) int {
 
 	/* Return to score command if that's where we came from. */
 	if mode == ScoreGame {
-		g.rspeak(int32(dungeon.GARNERED_POINTS), score, mxscr, g.Turns, g.Turns)
+		g.rspeak(int32(dungeon.GARNERED_POINTS), score, mxscr, g.Turns)
+		g.undoSummary()
 	}
 
 	return score
@@ -0,0 +1,181 @@
+package advent
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+const (
+	SUGGEST_LIMIT = 3 // Suggestions offered by tab completion for an unknown word
+
+	SUGGEST_DID_YOU_MEAN = "Did you mean %s?"
+	SUGGEST_CORRECTED    = "(Taking \"%s\" to mean \"%s\".)"
+)
+
+// suggestion is a vocabulary word and how far a typed word is from it.
+type suggestion struct {
+	entry    VocabEntry
+	distance int
+}
+
+// maxSuggestDistance is the number of edits allowed between a typed word and
+// a suggestion. Short words have too many near neighbours to guess at.
+func maxSuggestDistance(key string) int {
+	switch {
+	case len(key) <= 2:
+		return 0
+	case len(key) <= 4:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent letters needed to turn one into the other.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(b)]
+}
+
+// Entries returns every word in the vocabulary.
+func (v *Vocabulary) Entries() []VocabEntry {
+	var entries []VocabEntry
+	var walk func(n *vocabNode)
+	walk = func(n *vocabNode) {
+		entries = append(entries, n.all...)
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(v.root)
+	return entries
+}
+
+// suggestions returns the vocabulary words close to a typed word, nearest
+// first. Words are compared in the truncated form the parser uses, so
+// "LANTURN" is one edit from "LANTE". Ties go to motions, then objects, then
+// actions, as the parser prefers them. The magic word is never suggested.
+func (g *Game) suggestions(word string) []suggestion {
+	key := vocabKey(word)
+	limit := maxSuggestDistance(key)
+	if limit == 0 || len(g.language().vocabulary().Lookup(word)) > 0 {
+		return nil
+	}
+
+	zzword := vocabKey(strings.TrimRight(string(g.Zzword[:]), "\x00"))
+	seen := make(map[string]bool)
+
+	var found []suggestion
+	for _, e := range g.language().vocabulary().Entries() {
+		candidate := vocabKey(e.Word)
+		if seen[candidate] || candidate == zzword {
+			continue
+		}
+		if d := editDistance(key, candidate); d <= limit {
+			seen[candidate] = true
+			found = append(found, suggestion{e, d})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		if found[i].entry.WordType != found[j].entry.WordType {
+			return found[i].entry.WordType < found[j].entry.WordType
+		}
+		if found[i].entry.ID != found[j].entry.ID {
+			return found[i].entry.ID < found[j].entry.ID
+		}
+		return found[i].entry.Word < found[j].entry.Word
+	})
+
+	return found
+}
+
+// Suggest returns up to n known words the player may have meant by an
+// unknown word, nearest first. Long words are offered in full rather than
+// as the dungeon cuts them short: "LANTURN" gets "LANTERN", not "LANTE".
+// It returns nil for words the game knows and for words too short or too
+// far from anything to guess at.
+func (g *Game) Suggest(word string, n int) []string {
+	var words []string
+	for _, s := range g.suggestions(word) {
+		if len(words) == n {
+			break
+		}
+		words = append(words, g.language().fullWord(s.entry.Word))
+	}
+	return words
+}
+
+// correction returns the word to use in place of an unknown word when
+// auto-correct is on. Only a single nearest suggestion is trusted.
+func (g *Game) correction(word string) (string, bool) {
+	found := g.suggestions(word)
+	if len(found) == 0 || (len(found) > 1 && found[1].distance == found[0].distance) {
+		return "", false
+	}
+	return g.language().fullWord(found[0].entry.Word), true
+}
+
+// autoCorrect replaces the unknown words in a command with their corrections
+// and reports each replacement. It returns the command unchanged when there
+// is nothing it can safely correct.
+func (g *Game) autoCorrect(command string) string {
+	words := SplitWords(command)
+	changed := false
+
+	for i, word := range words {
+		if g.getVocabMetaData(word).ID != WORD_NOT_FOUND {
+			continue
+		}
+		if corrected, ok := g.correction(word); ok {
+			g.speak(SUGGEST_CORRECTED, strings.ToUpper(word), corrected)
+			words[i] = corrected
+			changed = true
+		}
+	}
+
+	if !changed {
+		return command
+	}
+	return strings.Join(words, " ")
+}
+
+// dontKnow tells the player a word isn't known, echoing shown, and offers
+// the nearest known word. Oldstyle leaves the original message alone.
+func (g *Game) dontKnow(shown string, word string) {
+	g.sspeak(dungeon.DONT_KNOW, shown)
+	if g.Settings.OldStyle {
+		return
+	}
+	if words := g.Suggest(word, 1); len(words) > 0 {
+		g.speak(SUGGEST_DID_YOU_MEAN, words[0])
+	}
+}
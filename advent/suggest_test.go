@@ -0,0 +1,185 @@
+package advent
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"LAMP", "LAMP", 0},
+		{"LAMP", "", 4},
+		{"NRTH", "NORTH", 1},   // insertion
+		{"NOORTH", "NORTH", 1}, // deletion
+		{"LANTU", "LANTE", 1},  // substitution
+		{"KYES", "KEYS", 1},    // transposition
+		{"BRIDE", "BIRD", 2},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	game := newStartedGame()
+
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"lanturn", "LANTERN"}, // Long words are offered in full, not as the dungeon cuts them
+		{"forrest", "FOREST"},
+		{"nrth", "NORTH"},
+		{"kyes", "KEYS"},
+		{"xyzzz", "XYZZY"}, // Magic words are part of the vocabulary
+		{"inventroy", ""},  // Known word, INVEN matches
+		{"lamp", ""},
+		{"qq", ""}, // Too short to guess at
+		{"frobnitz", ""},
+	}
+
+	for _, tt := range tests {
+		got := game.Suggest(tt.word, 1)
+		if tt.want == "" {
+			if len(got) != 0 {
+				t.Errorf("Suggest(%q) = %v, want none", tt.word, got)
+			}
+			continue
+		}
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("Suggest(%q) = %v, want [%s]", tt.word, got, tt.want)
+		}
+	}
+}
+
+// TestSuggestOffersOnlyKnownWords tests that a suggestion is always a word the
+// game knows, never the typed word spliced onto one, and never cut short
+func TestSuggestOffersOnlyKnownWords(t *testing.T) {
+	game := newStartedGame()
+
+	for _, typed := range []string{"bottel", "lanturn", "lantren", "forrest", "plovr", "wset", "kyes", "lanteyz"} {
+		for _, s := range game.Suggest(typed, SUGGEST_LIMIT) {
+			known := false
+			for _, e := range english().vocabulary().Lookup(s) {
+				known = known || fullWord(e.Word) == s
+			}
+			if !known {
+				t.Errorf("Suggest(%q) offered %s, which is not in the vocabulary", typed, s)
+			}
+			if s == "LANTE" || s == "FORES" {
+				t.Errorf("Suggest(%q) offered the truncated %s", typed, s)
+			}
+		}
+	}
+}
+
+func TestSuggestNeverOffersMagicWord(t *testing.T) {
+	game := newStartedGame()
+	zzword := string(game.Zzword[:5])
+
+	// One letter off the magic word
+	typo := []byte(zzword)
+	typo[0] = 'A' + (typo[0]-'A'+1)%26
+
+	for _, s := range game.Suggest(string(typo), 10) {
+		if vocabKey(s) == zzword {
+			t.Errorf("Suggest(%q) offered the magic word %s", typo, zzword)
+		}
+	}
+}
+
+func TestDontKnowSuggestsWord(t *testing.T) {
+	game := newStartedGame()
+	turns := game.Turns
+
+	game.ProcessCommand("nrth")
+
+	if !contains(game.Output, `"NRTH"`) || !contains(game.Output, "Did you mean NORTH?") {
+		t.Errorf("Expected unknown word and suggestion, got %q", game.Output)
+	}
+	if game.Turns != turns {
+		t.Errorf("Unknown word should not take a turn, turns went from %d to %d", turns, game.Turns)
+	}
+}
+
+func TestDontKnowNoSuggestionInOldStyle(t *testing.T) {
+	game := newOldStyleGame()
+
+	game.ProcessCommand("nrth")
+
+	if contains(game.Output, "Did you mean") {
+		t.Errorf("Oldstyle should not suggest words, got %q", game.Output)
+	}
+}
+
+func TestAutoCorrect(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.AutoCorrect = true
+	start := game.Loc
+
+	game.ProcessCommand("wset")
+
+	if !contains(game.Output, `(Taking "WSET" to mean "WEST".)`) {
+		t.Errorf("Expected correction note, got %q", game.Output)
+	}
+	if game.Newloc == start {
+		t.Errorf("Corrected WEST should have moved the player from %d", start)
+	}
+}
+
+// TestAutoCorrectLongWord tests that a long word is corrected to the word in
+// full, which the parser then cuts short as it would if it had been typed
+func TestAutoCorrectLongWord(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.AutoCorrect = true
+	game.Objects[dungeon.LAMP].Place = game.Loc
+
+	game.ProcessCommand("get lanturn")
+
+	if !contains(game.Output, `(Taking "LANTURN" to mean "LANTERN".)`) {
+		t.Errorf("Expected correction to LANTERN, got %q", game.Output)
+	}
+	if !game.toting(dungeon.LAMP) {
+		t.Errorf("Corrected LANTERN should have picked up the lamp")
+	}
+}
+
+func TestAutoCorrectLeavesUnknownWords(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.AutoCorrect = true
+
+	game.ProcessCommand("frobnitz")
+
+	if contains(game.Output, "Taking") || !contains(game.Output, `"FROBNITZ"`) {
+		t.Errorf("Expected plain unknown word message, got %q", game.Output)
+	}
+}
+
+func TestGetCorrections(t *testing.T) {
+	game := newStartedGame()
+
+	corrections := game.GetCorrections("get lanturn")
+	if !slices.Contains(corrections, "get lantern") {
+		t.Errorf("GetCorrections('get lanturn') should include 'get lantern', got %v", corrections)
+	}
+	if len(corrections) > SUGGEST_LIMIT {
+		t.Errorf("GetCorrections returned %d corrections, limit is %d", len(corrections), SUGGEST_LIMIT)
+	}
+
+	game.Settings.OldStyle = true
+	if corrections := game.GetCorrections("get lanturn"); corrections != nil {
+		t.Errorf("Oldstyle should have no corrections, got %v", corrections)
+	}
+}
@@ -0,0 +1,111 @@
+package advent
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TimelineEntry summarises one turn played on the current line of play.
+type TimelineEntry struct {
+	Turn       int32  // Turn number the command was played on
+	Command    string // The command as entered
+	Loc        int32  // Location the command was entered at
+	LocName    string // Short description of that location
+	ScoreDelta int    // Change in score caused by the turn
+}
+
+// Branch is an abandoned line of play that can be switched back to.
+type Branch struct {
+	Snapshots []Snapshot // States before each turn on the branch
+	Tip       Game       // The state at the end of the branch
+}
+
+// Timeline returns the turns recorded on the current line of play, oldest
+// first. Only turns still held as snapshots (see UndoDepth) are included.
+func (g *Game) Timeline() []TimelineEntry {
+	entries := make([]TimelineEntry, 0, len(g.Snapshots))
+
+	for i, snap := range g.Snapshots {
+		after := g
+		if i+1 < len(g.Snapshots) {
+			after = &g.Snapshots[i+1].State
+		}
+
+		entries = append(entries, TimelineEntry{
+			Turn:       snap.State.Turns + 1,
+			Command:    snap.Command,
+			Loc:        snap.State.Loc,
+			LocName:    g.locationName(snap.State.Loc),
+			ScoreDelta: after.GetScore() - snap.State.GetScore(),
+		})
+	}
+
+	return entries
+}
+
+// BranchFrom rewinds the game to the state before the given timeline entry.
+// The turns being rewound are kept as a branch that SwitchBranch can return
+// to later.
+func (g *Game) BranchFrom(index int) error {
+	if index < 0 || index >= len(g.Snapshots) {
+		return fmt.Errorf("no turn %d in the timeline", index)
+	}
+
+	turns := len(g.Snapshots) - index
+	state := g.Snapshots[index].State
+
+	g.Branches = append(g.Branches, g.currentBranch())
+	g.Snapshots = append([]Snapshot(nil), g.Snapshots[:index]...)
+
+	g.restoreSnapshot(state)
+	g.Undos += int32(turns)
+
+	return nil
+}
+
+// SwitchBranch makes an abandoned branch the current line of play. The line
+// being left becomes a branch in its place.
+func (g *Game) SwitchBranch(index int) error {
+	if index < 0 || index >= len(g.Branches) {
+		return fmt.Errorf("no branch %d", index)
+	}
+
+	branch := g.Branches[index]
+	g.Branches[index] = g.currentBranch()
+
+	g.restoreSnapshot(branch.Tip)
+	g.Snapshots = branch.Snapshots
+
+	return nil
+}
+
+// currentBranch captures the current line of play as a Branch.
+func (g *Game) currentBranch() Branch {
+	tip := *g
+	tip.Snapshots = nil
+	tip.Branches = nil
+	tip.Notebook = g.Notebook.clone()
+	tip.MapEdges = slices.Clone(g.MapEdges)
+
+	return Branch{
+		Snapshots: append([]Snapshot(nil), g.Snapshots...),
+		Tip:       tip,
+	}
+}
+
+// locationName returns the short description of a location for display, in
+// the game's locale.
+func (g *Game) locationName(loc int32) string {
+	if loc <= 0 || int(loc) >= len(dungeon.Locations) {
+		return "Nowhere"
+	}
+
+	name := g.localise(dungeon.Locations[loc].Description.Small)
+	if name == "" {
+		name = fmt.Sprintf("Location %d", loc)
+	}
+
+	return name
+}
@@ -0,0 +1,133 @@
+package advent
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestTimeline tests that each turn is listed with its command and location
+func TestTimeline(t *testing.T) {
+	game := newStartedGame()
+	startLoc := game.Loc
+
+	game.ProcessCommand("look")
+	game.ProcessCommand("east")
+	game.DoMove()
+	game.ProcessCommand("inventory")
+
+	timeline := game.Timeline()
+	if len(timeline) != 3 {
+		t.Fatalf("Timeline length: got %d, want 3", len(timeline))
+	}
+
+	if timeline[0].Command != "look" || timeline[0].Turn != 1 || timeline[0].Loc != startLoc {
+		t.Errorf("Unexpected first entry: %+v", timeline[0])
+	}
+	if timeline[2].Command != "inventory" || timeline[2].Loc == startLoc {
+		t.Errorf("Unexpected last entry: %+v", timeline[2])
+	}
+	if timeline[0].LocName == "" {
+		t.Error("Timeline entries should have a location name")
+	}
+}
+
+// TestTimelineScoreDelta tests that score changes are attributed to turns
+func TestTimelineScoreDelta(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessCommand("look")
+	game.Dflag = 1 // Getting well into the cave is worth 25 points
+	game.ProcessCommand("look")
+
+	timeline := game.Timeline()
+	if timeline[0].ScoreDelta != 25 {
+		t.Errorf("ScoreDelta: got %d, want 25", timeline[0].ScoreDelta)
+	}
+	if timeline[1].ScoreDelta != 0 {
+		t.Errorf("ScoreDelta: got %d, want 0", timeline[1].ScoreDelta)
+	}
+}
+
+// TestBranchFromAndSwitch tests branching and switching back to the old line
+func TestBranchFromAndSwitch(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+
+	game.ProcessCommand("look")
+	game.ProcessCommand("get lamp")
+	game.ProcessCommand("inventory")
+
+	if err := game.BranchFrom(1); err != nil {
+		t.Fatalf("BranchFrom returned error: %v", err)
+	}
+
+	if game.Turns != 1 {
+		t.Errorf("Turns after branching: got %d, want 1", game.Turns)
+	}
+	if game.Objects[dungeon.LAMP].Place == CARRIED {
+		t.Error("Lamp should not be carried after branching before 'get lamp'")
+	}
+	if len(game.Branches) != 1 {
+		t.Fatalf("Branches: got %d, want 1", len(game.Branches))
+	}
+
+	// Play on the new branch, then switch back
+	game.ProcessCommand("score")
+	if err := game.SwitchBranch(0); err != nil {
+		t.Fatalf("SwitchBranch returned error: %v", err)
+	}
+
+	if game.Turns != 3 {
+		t.Errorf("Turns after switching back: got %d, want 3", game.Turns)
+	}
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Error("Lamp should be carried on the original branch")
+	}
+	if len(game.Snapshots) != 3 {
+		t.Errorf("Snapshots after switching back: got %d, want 3", len(game.Snapshots))
+	}
+
+	// The abandoned branch is the one we just left
+	if len(game.Branches) != 1 {
+		t.Fatalf("Branches after switching back: got %d, want 1", len(game.Branches))
+	}
+	if game.Branches[0].Tip.Turns != 2 {
+		t.Errorf("Left branch tip turn: got %d, want 2", game.Branches[0].Tip.Turns)
+	}
+}
+
+// TestBranchFromInvalid tests branching from a turn that doesn't exist
+func TestBranchFromInvalid(t *testing.T) {
+	game := newStartedGame()
+
+	if err := game.BranchFrom(0); err == nil {
+		t.Error("Expected error branching from an empty timeline")
+	}
+	if err := game.SwitchBranch(0); err == nil {
+		t.Error("Expected error switching to a missing branch")
+	}
+}
+
+// TestBranchKeepsItsMap tests that playing on after branching doesn't change
+// the map of the branch left behind
+func TestBranchKeepsItsMap(t *testing.T) {
+	game := newStartedGame()
+	playNotebookCommands(game, "east", "west", "south", "south", "north", "north", "west", "east")
+
+	if err := game.BranchFrom(6); err != nil {
+		t.Fatalf("BranchFrom returned error: %v", err)
+	}
+	tip := slices.Clone(game.Branches[0].Tip.MapEdges)
+	snapshot := slices.Clone(game.Branches[0].Snapshots[6].State.MapEdges)
+
+	playNotebookCommands(game, "south", "south", "south")
+
+	if !slices.Equal(game.Branches[0].Tip.MapEdges, tip) {
+		t.Errorf("Branch tip map changed:\n got %v\nwant %v", game.Branches[0].Tip.MapEdges, tip)
+	}
+	if !slices.Equal(game.Branches[0].Snapshots[6].State.MapEdges, snapshot) {
+		t.Errorf("Branch snapshot map changed:\n got %v\nwant %v", game.Branches[0].Snapshots[6].State.MapEdges, snapshot)
+	}
+}
@@ -0,0 +1,140 @@
+package advent
+
+import (
+	"slices"
+	"strconv"
+)
+
+const (
+	UNDO_DEPTH = 10 // Default number of turns that can be undone
+
+	UNDO_DONE    = "Undone %d turn%S."
+	UNDO_NOTHING = "There is nothing to undo."
+	UNDO_USAGE   = "Usage: UNDO or UNDO <number of turns>."
+	UNDO_SUMMARY = "You rewound %d turn%S along the way."
+)
+
+// Snapshot records the game state as it was before a turn was played.
+type Snapshot struct {
+	Command string // The command that was played from this state
+	State   Game   // The game state before the command was processed
+}
+
+// UndoEnabled reports whether the game is keeping snapshots for UNDO. The
+// original game had no UNDO so oldstyle never does.
+func (g *Game) UndoEnabled() bool {
+	return !g.Settings.NoUndo && !g.Settings.OldStyle && g.Settings.UndoDepth > 0
+}
+
+// UndoAvailable returns the number of turns that can currently be undone.
+func (g *Game) UndoAvailable() int {
+	return len(g.Snapshots)
+}
+
+// pushSnapshot records the current state before the given command is played.
+// Only the most recent UndoDepth snapshots are kept.
+func (g *Game) pushSnapshot(command string) {
+	if !g.UndoEnabled() {
+		return
+	}
+
+	state := *g
+	state.Snapshots = nil
+	state.Branches = nil
+	state.OnQueryResponse = nil
+	state.Notebook = g.Notebook.clone()
+	state.MapEdges = slices.Clone(g.MapEdges)
+
+	g.Snapshots = append(g.Snapshots, Snapshot{Command: command, State: state})
+	if len(g.Snapshots) > g.Settings.UndoDepth {
+		g.Snapshots = g.Snapshots[len(g.Snapshots)-g.Settings.UndoDepth:]
+	}
+}
+
+// restoreSnapshot replaces the game state with a snapshot. Like LoadFromFile,
+// settings, locale, tracing and script state belong to the session rather
+// than the game and are preserved, as is the running undo count.
+func (g *Game) restoreSnapshot(state Game) {
+	snapshots := g.Snapshots
+	branches := g.Branches
+	settings := g.Settings
+	locale := g.locale
+	ctx := g.Ctx
+	locationSpan := g.LocationSpan
+	locationCtx := g.LocationCtx
+	scriptCommands := g.ScriptCommands
+	scriptIndex := g.ScriptIndex
+	undos := g.Undos
+
+	*g = state
+	g.Notebook = state.Notebook.clone()
+	g.MapEdges = slices.Clone(state.MapEdges)
+
+	g.Snapshots = snapshots
+	g.Branches = branches
+	g.Settings = settings
+	g.locale = locale
+	g.Ctx = ctx
+	g.LocationSpan = locationSpan
+	g.LocationCtx = locationCtx
+	g.ScriptCommands = scriptCommands
+	g.ScriptIndex = scriptIndex
+	g.Undos = undos
+}
+
+// Undo rewinds the game by up to the given number of turns and returns how
+// many turns were actually undone.
+func (g *Game) Undo(turns int) int {
+	if !g.UndoEnabled() || turns <= 0 || len(g.Snapshots) == 0 {
+		return 0
+	}
+
+	if turns > len(g.Snapshots) {
+		turns = len(g.Snapshots)
+	}
+
+	idx := len(g.Snapshots) - turns
+	state := g.Snapshots[idx].State
+	g.Snapshots = g.Snapshots[:idx]
+
+	g.restoreSnapshot(state)
+	g.Undos += int32(turns)
+
+	return turns
+}
+
+// undo handles the UNDO [n] command.
+func (g *Game) undo(words []string) {
+	turns := 1
+	if len(words) > 1 {
+		n, err := strconv.Atoi(words[1])
+		if err != nil || n <= 0 {
+			g.speak(UNDO_USAGE)
+			return
+		}
+		turns = n
+	}
+
+	undone := g.Undo(turns)
+	if undone == 0 {
+		g.speak(UNDO_NOTHING)
+		return
+	}
+
+	g.speak(UNDO_DONE, undone)
+	g.DescribeLocation()
+	g.ListObjects()
+}
+
+// isUndoCommand reports whether the (upper cased) words form an UNDO command
+// that should be handled before the normal parser sees it.
+func (g *Game) isUndoCommand(words []string) bool {
+	return g.UndoEnabled() && len(words) > 0 && len(words) <= 2 && words[0] == "UNDO"
+}
+
+// undoSummary adds the undo count to the score summary.
+func (g *Game) undoSummary() {
+	if g.Undos > 0 {
+		g.speak(UNDO_SUMMARY, int(g.Undos))
+	}
+}
@@ -0,0 +1,150 @@
+package advent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestUndoMove tests that UNDO returns the player to where they were
+func TestUndoMove(t *testing.T) {
+	game := newStartedGame()
+	startLoc := game.Loc
+
+	game.ProcessCommand("east")
+	game.DoMove()
+	if game.Loc == startLoc {
+		t.Fatalf("Expected to move away from %d", startLoc)
+	}
+
+	if err := game.ProcessCommand("undo"); err != nil {
+		t.Fatalf("ProcessCommand('undo') returned error: %v", err)
+	}
+
+	if game.Loc != startLoc {
+		t.Errorf("Loc after undo: got %d, want %d", game.Loc, startLoc)
+	}
+	if game.Turns != 0 {
+		t.Errorf("Turns after undo: got %d, want 0", game.Turns)
+	}
+	if game.Undos != 1 {
+		t.Errorf("Undos: got %d, want 1", game.Undos)
+	}
+}
+
+// TestUndoMultipleTurns tests rewinding several turns at once
+func TestUndoMultipleTurns(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.LAMP].Place = game.Loc
+
+	game.ProcessCommand("get lamp")
+	game.ProcessCommand("drop lamp")
+	game.ProcessCommand("get lamp")
+
+	if game.UndoAvailable() != 3 {
+		t.Fatalf("UndoAvailable: got %d, want 3", game.UndoAvailable())
+	}
+
+	game.ProcessCommand("undo 2")
+
+	if game.Objects[dungeon.LAMP].Place != CARRIED {
+		t.Errorf("Lamp should be carried after undoing two turns, got place=%d", game.Objects[dungeon.LAMP].Place)
+	}
+	if game.Turns != 1 {
+		t.Errorf("Turns after undo 2: got %d, want 1", game.Turns)
+	}
+	if game.Undos != 2 {
+		t.Errorf("Undos: got %d, want 2", game.Undos)
+	}
+	if game.UndoAvailable() != 1 {
+		t.Errorf("UndoAvailable: got %d, want 1", game.UndoAvailable())
+	}
+}
+
+// TestUndoNothing tests UNDO with no history
+func TestUndoNothing(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessCommand("undo")
+
+	if game.Output != UNDO_NOTHING {
+		t.Errorf("Expected %q, got %q", UNDO_NOTHING, game.Output)
+	}
+	if game.Turns != 0 {
+		t.Errorf("UNDO should not count as a turn, got %d turns", game.Turns)
+	}
+}
+
+// TestUndoDepth tests that only UndoDepth turns are kept
+func TestUndoDepth(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.UndoDepth = 2
+
+	for i := 0; i < 5; i++ {
+		game.ProcessCommand("look")
+	}
+
+	if game.UndoAvailable() != 2 {
+		t.Errorf("UndoAvailable: got %d, want 2", game.UndoAvailable())
+	}
+
+	if undone := game.Undo(10); undone != 2 {
+		t.Errorf("Undo(10): got %d, want 2", undone)
+	}
+	if game.Turns != 3 {
+		t.Errorf("Turns after undo: got %d, want 3", game.Turns)
+	}
+}
+
+// TestUndoDisabled tests that purist runs don't know the UNDO word
+func TestUndoDisabled(t *testing.T) {
+	game := newStartedGame()
+	game.Settings.NoUndo = true
+
+	game.ProcessCommand("look")
+	game.ProcessCommand("undo")
+
+	if game.UndoAvailable() != 0 {
+		t.Errorf("No snapshots should be kept when undo is disabled, got %d", game.UndoAvailable())
+	}
+	if game.Undos != 0 {
+		t.Errorf("Undos: got %d, want 0", game.Undos)
+	}
+	if strings.Contains(game.Output, "Undone") || game.Output == UNDO_NOTHING {
+		t.Errorf("UNDO should not be handled when disabled, got: %s", game.Output)
+	}
+}
+
+// TestUndoPreservesSession tests that settings and scripts survive an undo
+func TestUndoPreservesSession(t *testing.T) {
+	game := newStartedGame()
+	game.ScriptCommands = []string{"look", "score"}
+
+	game.ProcessCommand("look")
+	game.ScriptIndex = 1
+	game.Settings.UndoDepth = 5
+
+	game.Undo(1)
+
+	if game.ScriptIndex != 1 {
+		t.Errorf("ScriptIndex: got %d, want 1", game.ScriptIndex)
+	}
+	if game.Settings.UndoDepth != 5 {
+		t.Errorf("UndoDepth: got %d, want 5", game.Settings.UndoDepth)
+	}
+}
+
+// TestUndoScoreSummary tests that the undo count appears in the score
+func TestUndoScoreSummary(t *testing.T) {
+	game := newStartedGame()
+
+	game.ProcessCommand("look")
+	game.ProcessCommand("look")
+	game.ProcessCommand("undo 2")
+	game.ProcessCommand("score")
+
+	if !strings.Contains(game.Output, "You rewound 2 turns") {
+		t.Errorf("Score should include the undo count, got: %s", game.Output)
+	}
+}
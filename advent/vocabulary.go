@@ -0,0 +1,319 @@
+package advent
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// VocabEntry is one word of the dungeon vocabulary.
+type VocabEntry struct {
+	Word     string   // The word as it appears in the dungeon, upper cased
+	WordType WordType // MOTION, OBJECT or ACTION
+	ID       int      // Index into dungeon.Motions, dungeon.Objects or dungeon.Actions
+}
+
+// vocabNode is a node in the vocabulary trie. All holds the words whose
+// TOKLEN truncated form ends at this node, in motion, object, action order,
+// and entries the first of them for each word type.
+type vocabNode struct {
+	children map[byte]*vocabNode
+	entries  []VocabEntry
+	all      []VocabEntry
+}
+
+// Vocabulary is a prefix trie over the dungeon vocabulary, keyed on the
+// upper case TOKLEN truncated form of each word as the parser compares them.
+type Vocabulary struct {
+	root  *vocabNode
+	words int
+}
+
+// completionRank orders word types as completions list them.
+var completionRank = map[WordType]int{ACTION: 0, MOTION: 1, OBJECT: 2}
+
+// NewVocabulary builds the index for a set of dungeon vocabulary tables.
+func NewVocabulary(motions []dungeon.Motion_t, objects []dungeon.Object_t, actions []dungeon.Action_t) *Vocabulary {
+	return newVocabulary(tableWords(motions, objects, actions))
+}
+
+// tableWords returns the motion, object and action words of a set of
+// dungeon vocabulary tables.
+func tableWords(motions []dungeon.Motion_t, objects []dungeon.Object_t, actions []dungeon.Action_t) [3][]dungeon.String_Group_t {
+	var words [3][]dungeon.String_Group_t
+	for _, motion := range motions {
+		words[0] = append(words[0], motion.Words)
+	}
+	for _, object := range objects {
+		words[1] = append(words[1], object.Words)
+	}
+	for _, action := range actions {
+		words[2] = append(words[2], action.Words)
+	}
+	return words
+}
+
+// newVocabulary builds the index for the motion, object and action words of
+// a locale.
+func newVocabulary(words [3][]dungeon.String_Group_t) *Vocabulary {
+	v := &Vocabulary{root: &vocabNode{}}
+
+	for i, group := range words[0] {
+		v.addAll(group, MOTION, i)
+	}
+	for i, group := range words[1] {
+		v.addAll(group, OBJECT, i)
+	}
+	for i, group := range words[2] {
+		v.addAll(group, ACTION, i)
+	}
+
+	return v
+}
+
+func (v *Vocabulary) addAll(words dungeon.String_Group_t, wordType WordType, id int) {
+	for j := 0; j < words.N && j < len(words.Strs); j++ {
+		if words.Strs[j] != "" {
+			v.add(VocabEntry{Word: strings.ToUpper(words.Strs[j]), WordType: wordType, ID: id})
+		}
+	}
+}
+
+func (v *Vocabulary) add(entry VocabEntry) {
+	node := v.root
+	for _, c := range []byte(vocabKey(entry.Word)) {
+		if node.children == nil {
+			node.children = make(map[byte]*vocabNode)
+		}
+		next, ok := node.children[c]
+		if !ok {
+			next = &vocabNode{}
+			node.children[c] = next
+		}
+		node = next
+	}
+
+	node.all = append(node.all, entry)
+	v.words++
+
+	// Lookups find the first ID for each type, as the linear scans did
+	for _, e := range node.entries {
+		if e.WordType == entry.WordType {
+			return
+		}
+	}
+	node.entries = append(node.entries, entry)
+}
+
+// vocabKey returns the form of a word the parser compares on.
+func vocabKey(word string) string {
+	word = strings.ToUpper(word)
+	if len(word) > TOKLEN {
+		word = word[:TOKLEN]
+	}
+	return word
+}
+
+// dungeonTextWords counts the words of the dungeon's text, lower cased, for
+// fullWord to find the words the vocabulary cuts short.
+var dungeonTextWords = sync.OnceValue(func() map[string]int {
+	var texts []string
+	texts = append(texts, dungeon.Arbitrary_Messages...)
+	for _, loc := range dungeon.Locations {
+		texts = append(texts, loc.Description.Small, loc.Description.Big)
+	}
+	for _, obj := range dungeon.Objects {
+		texts = append(texts, obj.Inventory)
+		texts = append(texts, obj.Descriptions...)
+		texts = append(texts, obj.Texts...)
+		texts = append(texts, obj.Changes...)
+		texts = append(texts, obj.Sounds...)
+	}
+	for _, hint := range dungeon.Hints {
+		texts = append(texts, hint.Question, hint.Hint)
+	}
+
+	words := make(map[string]int)
+	for _, text := range texts {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return r < 'a' || r > 'z' }) {
+			words[word]++
+		}
+	}
+	return words
+})
+
+// inflections are the endings fullWord strips from the dungeon's words, each
+// with what the word's own ending was before it: "batteries" is "battery".
+// Plurals always go, bar those like "knives" whose singular the prefix can't
+// start and those like "debris" that aren't plurals; the others only when the
+// text has the word without them.
+var inflections = []struct {
+	suffix, base string
+	always       bool
+}{
+	{"ies", "y", true}, {"ing", "", false}, {"ing", "e", false}, {"ed", "", false}, {"ed", "e", false}, {"s", "", true},
+}
+
+// unspelt are the long words of the vocabulary that the dungeon's text never
+// spells out, or spells only inflected, so fullWord can't find them there.
+var unspelt = map[string]string{
+	"abrac": "abracadabra",
+	"ascen": "ascend",
+	"blowu": "blowup",
+	"captu": "capture",
+	"deton": "detonate",
+	"excav": "excavate",
+	"extin": "extinguish",
+	"ignit": "ignite",
+	"liste": "listen",
+	"mumbl": "mumble",
+	"onwar": "onward",
+	"outdo": "outdoors",
+	"perus": "peruse",
+	"placa": "placate",
+	"relea": "release",
+	"resta": "restart",
+	"retre": "retreat",
+	"score": "score",
+	"sesam": "sesame",
+	"shaza": "shazam",
+	"slabr": "slabroom",
+	"trave": "traverse",
+	"wizar": "wizard",
+}
+
+// fullWord returns a vocabulary word as a player would write it. The dungeon
+// keeps long words cut to TOKLEN letters, "LANTE" for LANTERN, so a word of
+// that length is taken to be the shortest word of the dungeon's text it
+// starts, less any inflection. A word the text uses whole stays as it is.
+func fullWord(word string) string {
+	if len(word) != TOKLEN {
+		return word
+	}
+	prefix := strings.ToLower(word)
+	if full, ok := unspelt[prefix]; ok {
+		return strings.ToUpper(full)
+	}
+	words := dungeonTextWords()
+
+	best := ""
+	for w := range words {
+		if !strings.HasPrefix(w, prefix) {
+			continue
+		}
+		w = uninflect(w, prefix, words)
+		if w == prefix {
+			return word
+		}
+		if best == "" || len(w) < len(best) || (len(w) == len(best) && (words[w] > words[best] || (words[w] == words[best] && w < best))) {
+			best = w
+		}
+	}
+	if best == "" {
+		return word
+	}
+	return strings.ToUpper(best)
+}
+
+// uninflect strips an inflection from a word of the dungeon's text that starts
+// prefix, keeping to words that still start it.
+func uninflect(w, prefix string, words map[string]int) string {
+	for _, in := range inflections {
+		stem, ok := strings.CutSuffix(w, in.suffix)
+		if !ok || strings.HasSuffix(stem, "s") || strings.HasSuffix(stem, "ve") || strings.HasSuffix(stem, "i") || !strings.HasPrefix(stem+in.base, prefix) {
+			continue
+		}
+		if base := stem + in.base; (in.always && len(base) >= len(prefix)) || words[base] > 0 {
+			return base
+		}
+	}
+	return w
+}
+
+func (v *Vocabulary) find(key string) *vocabNode {
+	node := v.root
+	for i := 0; i < len(key); i++ {
+		node = node.children[key[i]]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Lookup returns the vocabulary entries a raw word matches, at most one per
+// word type, in motion, object, action order. More than one entry means the
+// word is ambiguous and the parser's type preference decides.
+func (v *Vocabulary) Lookup(raw string) []VocabEntry {
+	if raw == "" {
+		return nil
+	}
+	if node := v.find(vocabKey(raw)); node != nil {
+		return node.entries
+	}
+	return nil
+}
+
+// LookupType returns the ID of the word of the given type that a raw word
+// matches, or WORD_NOT_FOUND.
+func (v *Vocabulary) LookupType(raw string, wordType WordType) int {
+	for _, e := range v.Lookup(raw) {
+		if e.WordType == wordType {
+			return e.ID
+		}
+	}
+	return WORD_NOT_FOUND
+}
+
+// IsAmbiguous reports whether a raw word is more than one type of word.
+func (v *Vocabulary) IsAmbiguous(raw string) bool {
+	return len(v.Lookup(raw)) > 1
+}
+
+// WithPrefix returns every entry whose word starts with prefix, ordered by
+// word type (actions, motions, then objects as completions list them) and
+// then by ID.
+func (v *Vocabulary) WithPrefix(prefix string) []VocabEntry {
+	prefix = strings.ToUpper(prefix)
+	if prefix == "" {
+		return nil
+	}
+
+	node := v.find(vocabKey(prefix))
+	if node == nil {
+		return nil
+	}
+
+	var matches []VocabEntry
+	var walk func(n *vocabNode)
+	walk = func(n *vocabNode) {
+		for _, e := range n.all {
+			if strings.HasPrefix(e.Word, prefix) {
+				matches = append(matches, e)
+			}
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(node)
+
+	sort.Slice(matches, func(i, j int) bool {
+		if completionRank[matches[i].WordType] != completionRank[matches[j].WordType] {
+			return completionRank[matches[i].WordType] < completionRank[matches[j].WordType]
+		}
+		if matches[i].ID != matches[j].ID {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].Word < matches[j].Word
+	})
+
+	return matches
+}
+
+// Len returns the number of words in the vocabulary.
+func (v *Vocabulary) Len() int {
+	return v.words
+}
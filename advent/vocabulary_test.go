@@ -0,0 +1,191 @@
+package advent
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// linearVocabID is the linear scan the index replaced, kept as a reference.
+func linearVocabID(rawWord string, wordType WordType) int {
+	scan := func(n int, words func(i int) dungeon.String_Group_t) int {
+		for i := 0; i < n; i++ {
+			w := words(i)
+			for j := 0; j < w.N; j++ {
+				if strnCaseCmpEqual(rawWord, w.Strs[j], TOKLEN) {
+					return i
+				}
+			}
+		}
+		return WORD_NOT_FOUND
+	}
+
+	switch wordType {
+	case MOTION:
+		return scan(dungeon.NMOTIONS, func(i int) dungeon.String_Group_t { return dungeon.Motions[i].Words })
+	case OBJECT:
+		return scan(dungeon.NOBJECTS+1, func(i int) dungeon.String_Group_t { return dungeon.Objects[i].Words })
+	case ACTION:
+		return scan(dungeon.NACTIONS, func(i int) dungeon.String_Group_t { return dungeon.Actions[i].Words })
+	}
+	return WORD_NOT_FOUND
+}
+
+// vocabTestWords returns every vocabulary word plus some near misses.
+func vocabTestWords() []string {
+	words := []string{"NORTHWARD", "lantern", "Xyzzy", "PLUGHS", "QQQQ", "lam", "w"}
+	for _, m := range dungeon.Motions {
+		words = append(words, m.Words.Strs...)
+	}
+	for _, o := range dungeon.Objects {
+		words = append(words, o.Words.Strs...)
+	}
+	for _, a := range dungeon.Actions {
+		words = append(words, a.Words.Strs...)
+	}
+	return words
+}
+
+// TestVocabularyMatchesLinearScan tests the index against the old scans
+func TestVocabularyMatchesLinearScan(t *testing.T) {
+	for _, word := range vocabTestWords() {
+		if word == "" {
+			continue
+		}
+		for _, wordType := range []WordType{MOTION, OBJECT, ACTION} {
+			want := linearVocabID(word, wordType)
+			if got := english().vocabulary().LookupType(word, wordType); got != want {
+				t.Errorf("LookupType(%q, %d): got %d, want %d", word, wordType, got, want)
+			}
+		}
+	}
+}
+
+// TestVocabularyAmbiguity tests that words of more than one type are recorded
+func TestVocabularyAmbiguity(t *testing.T) {
+	v := english().vocabulary()
+
+	if v.IsAmbiguous("LAMP") {
+		t.Errorf("LAMP should only be an object, got %v", v.Lookup("LAMP"))
+	}
+
+	// Find a word that is both a motion and something else
+	found := false
+	for _, m := range dungeon.Motions {
+		for _, w := range m.Words.Strs {
+			if w != "" && v.IsAmbiguous(w) {
+				found = true
+				entries := v.Lookup(w)
+				if entries[0].WordType != MOTION {
+					t.Errorf("%q: motions should come first, got %v", w, entries)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected at least one ambiguous motion word")
+	}
+}
+
+// TestVocabularyWithPrefix tests prefix search
+func TestVocabularyWithPrefix(t *testing.T) {
+	v := english().vocabulary()
+
+	matches := v.WithPrefix("nor")
+	if len(matches) == 0 || matches[0].Word != "NORTH" || matches[0].WordType != MOTION {
+		t.Errorf("WithPrefix(nor): got %v", matches)
+	}
+
+	for _, m := range v.WithPrefix("la") {
+		if !strings.HasPrefix(m.Word, "LA") {
+			t.Errorf("WithPrefix(la) returned %q", m.Word)
+		}
+	}
+
+	if got := v.WithPrefix("northwest"); len(got) != 0 {
+		t.Errorf("Prefix longer than any word should match nothing, got %v", got)
+	}
+	if got := v.WithPrefix(""); got != nil {
+		t.Errorf("Empty prefix should match nothing, got %v", got)
+	}
+}
+
+// TestVocabularyOldStyleIgnore tests that oldstyle still skips ignored letters
+func TestVocabularyOldStyleIgnore(t *testing.T) {
+	if id := english().getActionVocabID("g", true); id != WORD_NOT_FOUND {
+		t.Errorf("G should be unknown in oldstyle, got %d", id)
+	}
+	if id := english().getActionVocabID("g", false); id == WORD_NOT_FOUND {
+		t.Error("G should be known outside oldstyle")
+	}
+}
+
+func BenchmarkVocabLookupIndexed(b *testing.B) {
+	words := vocabTestWords()
+	game := newTestGame()
+	english().vocabulary()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.getVocabMetaData(strings.ToUpper(words[i%len(words)]))
+	}
+}
+
+func BenchmarkVocabLookupLinear(b *testing.B) {
+	words := vocabTestWords()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		word := strings.ToUpper(words[i%len(words)])
+		if linearVocabID(word, MOTION) == WORD_NOT_FOUND && linearVocabID(word, OBJECT) == WORD_NOT_FOUND {
+			linearVocabID(word, ACTION)
+		}
+	}
+}
+
+func BenchmarkCompletions(b *testing.B) {
+	game := newStartedGame()
+	prefixes := []string{"n", "ge", "la", "dro", "s", "xy", "in"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.GetCompletions(prefixes[i%len(prefixes)])
+	}
+}
+
+// TestCompletionsSharedObjectWord tests completing a word shared by two objects
+func TestCompletionsSharedObjectWord(t *testing.T) {
+	game := newStartedGame()
+	game.Objects[dungeon.ROD].Place = int32(dungeon.LOC_NOWHERE)
+	game.Objects[dungeon.ROD2].Place = game.Loc
+
+	completions := game.GetCompletions("ro")
+	if !slices.Contains(completions, "rod") {
+		t.Errorf("Expected rod in completions, got %v", completions)
+	}
+}
+
+// TestFullWord tests that words the dungeon cuts short are given in full
+func TestFullWord(t *testing.T) {
+	tests := map[string]string{
+		"LANTE": "LANTERN",
+		"FORES": "FOREST",
+		"UPSTR": "UPSTREAM",
+		"DOWNS": "DOWNSTREAM",
+		"EXAMI": "EXAMINE",
+		"BATTE": "BATTERY",
+		"KNIVE": "KNIVES",
+		"DEBRI": "DEBRIS",
+		"SCORE": "SCORE", // A whole word the text only inflects
+		"LAMP":  "LAMP",
+		"OUTDO": "OUTDOORS", // Nothing in the text to finish it
+	}
+
+	for word, want := range tests {
+		if got := fullWord(word); got != want {
+			t.Errorf("fullWord(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package bench plays AI games headlessly and reports how well the model did,
+// so models and prompts can be compared on the same seeds.
+package bench
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andrewsjg/goAdventure/advent"
+	"github.com/andrewsjg/goAdventure/ollama"
+)
+
+// Config describes a benchmark run.
+type Config struct {
+	Backend   ollama.BackendConfig // The model to play; each game gets its own client, with its seed as the Game
+	Games     int                  // Games to play
+	MaxTurns  int                  // Commands the AI may give before a game is stopped
+	SeedStart int                  // Seed of the first game; each later game uses the next seed
+	Parallel  int                  // Games played at once
+	Attempts  int                  // Tries the AI gets at a command the game understands
+	Rewards   ollama.RewardWeights // What the AI is rewarded for; zero for the defaults
+}
+
+// Result is how one game went.
+type Result struct {
+	Game          int                 `json:"game"`
+	Seed          int                 `json:"seed"`
+	Score         int                 `json:"score"`
+	Turns         int                 `json:"turns"`    // Turns as the game counts them
+	Commands      int                 `json:"commands"` // Commands and answers the AI gave
+	Deaths        int                 `json:"deaths"`
+	Treasures     int                 `json:"treasures"` // Treasures found, deposited or not
+	Locations     int                 `json:"locations"` // Different locations visited
+	Invalid       int                 `json:"invalid"`   // Commands the game wouldn't understand
+	Stuck         int                 `json:"stuck"`     // Times the AI got stuck going round in circles
+	Fallbacks     int                 `json:"fallbacks"` // Commands chosen for the AI to get it unstuck
+	Reward        float64             `json:"reward"`    // Shaped reward for the whole game
+	Rewards       ollama.RewardEvents `json:"rewards"`   // What the reward was given for
+	Finished      bool                `json:"finished"`  // The game ended before MaxTurns
+	LatencyMeanMs float64             `json:"latency_mean_ms"`
+	LatencyMaxMs  float64             `json:"latency_max_ms"`
+	Error         string              `json:"error,omitempty"` // Why the AI stopped playing, if it failed
+}
+
+// The engine keeps some of its state in package variables, so games take
+// turns with it while their models think in parallel.
+var engine sync.Mutex
+
+// Run plays the games, calling done, if not nil, as each one finishes, and
+// returns the results in seed order. Cancelling ctx stops the games being
+// played, recording the error in their results, and skips the rest.
+func Run(ctx context.Context, cfg Config, done func(Result)) []Result {
+	parallel := max(cfg.Parallel, 1)
+
+	results := make([]Result, cfg.Games)
+	slots := make(chan struct{}, parallel)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := range cfg.Games {
+		results[i] = Result{Game: i + 1, Seed: cfg.SeedStart + i}
+		if ctx.Err() != nil {
+			results[i].Error = ctx.Err().Error()
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			play(ctx, cfg, &results[i])
+			if done != nil {
+				mu.Lock()
+				done(results[i])
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// play plays one game as the classic terminal loop does, without the output
+// or the pauses between moves, filling in result.
+func play(ctx context.Context, cfg Config, result *Result) {
+	engine.Lock()
+	game := advent.NewGame(result.Seed, "", "", "", false, false, false, nil)
+	engine.Unlock()
+
+	backend := cfg.Backend
+	backend.Game = result.Seed
+	client, err := ollama.NewLLM(backend)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	player := ollama.NewPlayer(client, false)
+	player.SetValidator(func(command string) error {
+		engine.Lock()
+		defer engine.Unlock()
+		return game.Validate(command)
+	}, cfg.Attempts)
+	rewards := ollama.NewRewardTracker()
+	if cfg.Rewards != (ollama.RewardWeights{}) {
+		rewards.Weights = cfg.Rewards
+	}
+
+	var latency, slowest time.Duration
+	defer func() {
+		engine.Lock()
+		defer engine.Unlock()
+
+		result.Score = game.GetScore()
+		result.Turns = int(game.Turns)
+		result.Deaths = int(game.Numdie)
+		result.Treasures = game.TreasuresFound()
+		result.Locations = game.LocationsVisited()
+		result.Invalid += player.Rejected
+		result.Stuck = len(rewards.StuckEvents)
+		result.Fallbacks = player.Fallbacks
+		rewards.Observe(Progress(&game))
+		result.Reward = rewards.Total
+		result.Rewards = rewards.Totals
+		result.Finished = game.GameOver
+		if result.Commands > 0 {
+			result.LatencyMeanMs = milliseconds(latency / time.Duration(result.Commands))
+		}
+		result.LatencyMaxMs = milliseconds(slowest)
+	}()
+
+	for result.Commands < cfg.MaxTurns {
+		engine.Lock()
+		gc := nextContext(&game, rewards)
+		engine.Unlock()
+		if gc == nil {
+			return
+		}
+
+		start := time.Now()
+		command, _, err := player.GetCommand(ctx, gc)
+		took := time.Since(start)
+		if err != nil {
+			result.Error = err.Error()
+			return
+		}
+		result.Commands++
+		latency += took
+		slowest = max(slowest, took)
+
+		engine.Lock()
+		if !playCommand(&game, rewards, command) {
+			result.Invalid++
+		}
+		engine.Unlock()
+	}
+}
+
+// nextContext moves the game on until it needs a command or an answer from
+// the player and returns what the AI is told, or nil once the game is over.
+func nextContext(game *advent.Game, rewards *ollama.RewardTracker) *ollama.GameContext {
+	for !game.GameOver {
+		if !game.QueryFlag {
+			if game.Newloc != game.Loc {
+				game.DoMove()
+				game.DescribeLocation()
+				game.ListObjects()
+			}
+			if game.LocForced() {
+				game.MoveHere()
+				continue
+			}
+		}
+
+		return GameContext(game, rewards)
+	}
+	return nil
+}
+
+// playCommand plays the AI's command or answers the game's question with it,
+// reporting false if the game didn't understand it.
+func playCommand(game *advent.Game, rewards *ollama.RewardTracker, command string) bool {
+	if game.QueryFlag {
+		game.QueryResponse = command
+		game.QueryFlag = false
+		if game.OnQueryResponse != nil {
+			game.OnQueryResponse(game.QueryResponse, game)
+		}
+		return true
+	}
+
+	rewards.StartAction(int(game.Loc), command)
+	understood := game.Validate(command) == nil
+	if err := game.ProcessInput(command); err != nil {
+		understood = false
+	}
+	return understood
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
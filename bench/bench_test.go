@@ -0,0 +1,238 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/ollama"
+)
+
+// fakeConfig returns a run with the fake backend playing script.
+func fakeConfig(games, maxTurns int, script ...string) Config {
+	return Config{
+		Backend:   ollama.BackendConfig{Backend: ollama.BackendFake, Script: script},
+		Games:     games,
+		MaxTurns:  maxTurns,
+		SeedStart: 1,
+		Parallel:  2,
+		Attempts:  1,
+	}
+}
+
+// TestRun tests that each game is played to the turn limit on its own seed
+func TestRun(t *testing.T) {
+	var done int
+	results := Run(context.Background(), fakeConfig(3, 4, "NO", "EAST", "GET LAMP", "WEST"), func(Result) { done++ })
+
+	if len(results) != 3 || done != 3 {
+		t.Fatalf("Got %d results and %d done calls, want 3 of each", len(results), done)
+	}
+	for i, r := range results {
+		if r.Game != i+1 || r.Seed != i+1 {
+			t.Errorf("Result %d: got game %d seed %d", i, r.Game, r.Seed)
+		}
+		if r.Error != "" {
+			t.Errorf("Game %d failed: %s", r.Game, r.Error)
+		}
+		if r.Commands != 4 || r.Finished {
+			t.Errorf("Game %d: got %d commands, finished %v, want 4 unfinished", r.Game, r.Commands, r.Finished)
+		}
+		if r.Locations != 2 {
+			t.Errorf("Game %d: got %d locations, want 2", r.Game, r.Locations)
+		}
+		if r.Invalid != 0 {
+			t.Errorf("Game %d: got %d invalid commands, want 0", r.Game, r.Invalid)
+		}
+	}
+}
+
+// TestRunCountsInvalidCommands tests that commands the game doesn't know are counted
+func TestRunCountsInvalidCommands(t *testing.T) {
+	cfg := fakeConfig(1, 2, "NO", "FROBNICATE", "FROBNICATE")
+	cfg.Attempts = 2
+
+	r := Run(context.Background(), cfg, nil)[0]
+
+	// The first FROBNICATE is rejected and the second played regardless
+	if r.Invalid != 2 {
+		t.Errorf("Invalid: got %d, want 2", r.Invalid)
+	}
+}
+
+// TestRunGetsUnstuck tests that an AI going round in circles is noticed and moved on
+func TestRunGetsUnstuck(t *testing.T) {
+	r := Run(context.Background(), fakeConfig(1, 20, "NO", "XYZZY"), nil)[0]
+
+	if r.Stuck == 0 {
+		t.Error("Expected the AI to be found stuck")
+	}
+	if r.Fallbacks == 0 || r.Locations < 2 {
+		t.Errorf("Got %d fallbacks and %d locations, want the fallback to have gone somewhere", r.Fallbacks, r.Locations)
+	}
+}
+
+// TestRunRewards tests that a game's shaped reward is reported with what it was given for
+func TestRunRewards(t *testing.T) {
+	r := Run(context.Background(), fakeConfig(1, 4, "NO", "EAST", "GET LAMP", "WEST"), nil)[0]
+
+	if r.Rewards.NewLocations != 1 || r.Rewards.Turns != 3 || r.Rewards.Deaths != 0 {
+		t.Errorf("Rewards: got %+v, want a new location in 3 turns", r.Rewards)
+	}
+	if want := ollama.DefaultRewardWeights().Reward(r.Rewards); math.Abs(r.Reward-want) > 1e-9 {
+		t.Errorf("Reward: got %v, want %v", r.Reward, want)
+	}
+
+	cfg := fakeConfig(1, 4, "NO", "EAST", "GET LAMP", "WEST")
+	cfg.Rewards = ollama.RewardWeights{NewLocation: 10}
+	if r := Run(context.Background(), cfg, nil)[0]; r.Reward != 10 {
+		t.Errorf("Reward with new weights: got %v, want 10", r.Reward)
+	}
+}
+
+// TestRunCancelled tests that a cancelled run records why games weren't played
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, r := range Run(ctx, fakeConfig(2, 5), nil) {
+		if r.Error == "" || r.Commands != 0 {
+			t.Errorf("Game %d: got %d commands and error %q, want none and an error", r.Game, r.Commands, r.Error)
+		}
+	}
+}
+
+// TestRunReplaysCassette tests that a recorded run plays out the same way again
+func TestRunReplaysCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.cassette")
+	cassette, err := ollama.CreateCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := fakeConfig(2, 30, "NO", "EAST", "GET LAMP", "GET KEYS", "WEST", "SOUTH", "SOUTH", "SOUTH",
+		"UNLOCK GRATE", "DOWN", "LIGHT LAMP", "WEST", "WEST", "WEST", "DOWN", "SOUTH")
+	cfg.Backend.Cassette = cassette
+	recorded := Run(context.Background(), cfg, nil)
+	cassette.Close()
+
+	if cfg.Backend.Cassette, err = ollama.LoadCassette(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Backend.Backend = ollama.BackendReplay
+	cfg.Backend.Script = nil
+	replayed := Run(context.Background(), cfg, nil)
+
+	for i := range recorded {
+		want, got := recorded[i], replayed[i]
+		want.LatencyMeanMs, want.LatencyMaxMs = 0, 0
+		got.LatencyMeanMs, got.LatencyMaxMs = 0, 0
+		if got != want {
+			t.Errorf("Game %d: replayed %+v, recorded %+v", i+1, got, want)
+		}
+	}
+}
+
+// update re-records the cassette in testdata
+var update = flag.Bool("update", false, "re-record testdata/run.cassette")
+
+// TestReplayRecordedRun tests that the run recorded in testdata still plays
+// back. Its replies are keyed by the prompts the AI was sent, so a change to
+// them, as to FormatContext, misses the cassette. If the change is meant,
+// re-record it with go test ./bench -run TestReplayRecordedRun -update
+func TestReplayRecordedRun(t *testing.T) {
+	path := filepath.Join("testdata", "run.cassette")
+	cfg := fakeConfig(2, 20, "NO", "EAST", "GET LAMP", "GET KEYS", "GET FOOD", "GET BOTTLE", "WEST", "SOUTH", "SOUTH", "SOUTH",
+		"UNLOCK GRATE", "DOWN", "LIGHT LAMP", "WEST", "GET CAGE", "WEST", "WEST", "GET ROD", "WEST", "DOWN")
+	cfg.Parallel = 1 // Recorded in order, so re-recording changes only what changed
+
+	if *update {
+		cassette, err := ollama.CreateCassette(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Backend.Cassette = cassette
+		Run(context.Background(), cfg, nil)
+		cassette.Close()
+	}
+
+	cassette, err := ollama.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Backend = ollama.BackendConfig{Backend: ollama.BackendReplay, Cassette: cassette}
+
+	for _, r := range Run(context.Background(), cfg, nil) {
+		if strings.Contains(r.Error, "no recorded reply") {
+			t.Errorf("Game %d missed the cassette after %d commands; if the prompts changed on purpose, re-record it with -update:\n%s", r.Game, r.Commands, r.Error)
+		} else if r.Error != "" || r.Commands != cfg.MaxTurns {
+			t.Errorf("Game %d: got %d commands and error %q, want %d and none", r.Game, r.Commands, r.Error, cfg.MaxTurns)
+		}
+	}
+}
+
+// TestSummarise tests the aggregate of a run
+func TestSummarise(t *testing.T) {
+	s := Summarise([]Result{
+		{Score: 30, Finished: true},
+		{Score: 40, Error: "timed out"},
+		{Score: 50},
+	})
+
+	if s.Games != 3 || s.Finished != 1 || s.Failed != 1 {
+		t.Errorf("Got %d games, %d finished, %d failed", s.Games, s.Finished, s.Failed)
+	}
+	if s.Score != (Stat{Mean: 40, Min: 30, Max: 50}) {
+		t.Errorf("Score: got %+v", s.Score)
+	}
+	if !strings.Contains(s.String(), "score") {
+		t.Errorf("Summary table missing the score:\n%s", s)
+	}
+}
+
+// TestWriteCSV tests that there is a row per game after the header
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "csv", []Result{{Game: 1, Seed: 7, Score: 36, Error: "bad, very bad"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(csvHeader) {
+		t.Fatalf("Got rows %v", rows)
+	}
+	if rows[1][1] != "7" || rows[1][2] != "36" || rows[1][len(csvHeader)-1] != "bad, very bad" {
+		t.Errorf("Unexpected row %v", rows[1])
+	}
+}
+
+// TestWriteJSON tests that the report has the games and their summary
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "json", []Result{{Score: 10}, {Score: 20}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Games) != 2 || report.Summary.Score.Mean != 15 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+// TestWriteUnknownFormat tests that an unknown format is an error
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "xml", nil); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,48 @@
+package bench
+
+import (
+	"github.com/andrewsjg/goAdventure/advent"
+	"github.com/andrewsjg/goAdventure/ollama"
+)
+
+// GameContext returns what the AI is told about the game before its next
+// command. With a reward tracker, the AI's last command has played out, so it
+// is rewarded first and the context carries the feedback.
+func GameContext(game *advent.Game, rewards *ollama.RewardTracker) *ollama.GameContext {
+	ctx := &ollama.GameContext{
+		GameOutput:      game.Output,
+		LocationDesc:    game.GetLocationDescription(),
+		VisibleObjects:  game.GetVisibleObjects(),
+		Inventory:       game.InventoryDescriptions(),
+		Score:           game.GetScore(),
+		Turns:           int(game.Turns),
+		Hints:           game.GenerateHints(),
+		ValidActions:    game.GetAllVerbs(),
+		ValidDirections: game.GetAllDirections(),
+		ValidObjects:    game.GetInteractableObjects(),
+		Vocabulary:      game.GetAllWords(),
+		Memory:          game.NotebookSummary(),
+	}
+	if rewards != nil {
+		rewards.ObserveTurn(game.SpanContext(), Progress(game))
+		ctx.RewardFeedback = rewards.GetFeedback()
+		ctx.RewardGuide = rewards.Weights.Guide()
+		ctx.Stuck = rewards.Stuck()
+	}
+	return ctx
+}
+
+// Progress returns the game's tally of what the player has achieved, for the
+// AI's rewards.
+func Progress(game *advent.Game) ollama.Progress {
+	p := game.Progress()
+	return ollama.Progress{
+		Score:              p.Score,
+		Locations:          p.Locations,
+		TreasuresSeen:      p.TreasuresSeen,
+		TreasuresDeposited: p.TreasuresDeposited,
+		Puzzles:            p.Puzzles,
+		Deaths:             p.Deaths,
+		Turns:              p.Turns,
+	}
+}
@@ -0,0 +1,26 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/advent"
+	"github.com/andrewsjg/goAdventure/ollama"
+)
+
+// TestGameContext tests that the AI is told about the game, and about its
+// rewards only when they are being tracked
+func TestGameContext(t *testing.T) {
+	game := advent.NewGame(1, "", "", "", false, false, false, nil)
+
+	ctx := GameContext(&game, nil)
+	if ctx.LocationDesc == "" || len(ctx.ValidDirections) == 0 || len(ctx.Vocabulary) == 0 {
+		t.Errorf("Context is missing the game state: %+v", ctx)
+	}
+	if ctx.RewardGuide != "" || ctx.RewardFeedback != nil {
+		t.Errorf("Context without a tracker should have no rewards, got %q %v", ctx.RewardGuide, ctx.RewardFeedback)
+	}
+
+	if ctx := GameContext(&game, ollama.NewRewardTracker()); ctx.RewardGuide == "" {
+		t.Error("Context with a tracker should carry its reward guide")
+	}
+}
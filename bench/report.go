@@ -0,0 +1,164 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Stat summarises one measure across the games.
+type Stat struct {
+	Mean float64 `json:"mean"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+}
+
+// Summary is the aggregate of a run, for comparing one model or prompt with
+// another.
+type Summary struct {
+	Games     int  `json:"games"`
+	Finished  int  `json:"finished"` // Games that ended before the turn limit
+	Failed    int  `json:"failed"`   // Games the AI stopped playing with an error
+	Score     Stat `json:"score"`
+	Turns     Stat `json:"turns"`
+	Deaths    Stat `json:"deaths"`
+	Treasures Stat `json:"treasures"`
+	Locations Stat `json:"locations"`
+	Invalid   Stat `json:"invalid"`
+	Stuck     Stat `json:"stuck"`
+	Fallbacks Stat `json:"fallbacks"`
+	Reward    Stat `json:"reward"`
+	LatencyMs Stat `json:"latency_ms"` // Mean latency of each game
+}
+
+// Report is a run's results and their summary.
+type Report struct {
+	Games   []Result `json:"games"`
+	Summary Summary  `json:"summary"`
+}
+
+// Summarise works out the aggregate of a run.
+func Summarise(results []Result) Summary {
+	s := Summary{Games: len(results)}
+	stat := func(value func(r Result) float64) Stat {
+		var st Stat
+		for i, r := range results {
+			v := value(r)
+			st.Mean += v
+			if i == 0 || v < st.Min {
+				st.Min = v
+			}
+			if i == 0 || v > st.Max {
+				st.Max = v
+			}
+		}
+		if len(results) > 0 {
+			st.Mean /= float64(len(results))
+		}
+		return st
+	}
+
+	for _, r := range results {
+		if r.Finished {
+			s.Finished++
+		}
+		if r.Error != "" {
+			s.Failed++
+		}
+	}
+	s.Score = stat(func(r Result) float64 { return float64(r.Score) })
+	s.Turns = stat(func(r Result) float64 { return float64(r.Turns) })
+	s.Deaths = stat(func(r Result) float64 { return float64(r.Deaths) })
+	s.Treasures = stat(func(r Result) float64 { return float64(r.Treasures) })
+	s.Locations = stat(func(r Result) float64 { return float64(r.Locations) })
+	s.Invalid = stat(func(r Result) float64 { return float64(r.Invalid) })
+	s.Stuck = stat(func(r Result) float64 { return float64(r.Stuck) })
+	s.Fallbacks = stat(func(r Result) float64 { return float64(r.Fallbacks) })
+	s.Reward = stat(func(r Result) float64 { return r.Reward })
+	s.LatencyMs = stat(func(r Result) float64 { return r.LatencyMeanMs })
+	return s
+}
+
+// String formats the summary as a table.
+func (s Summary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d games: %d finished, %d failed\n", s.Games, s.Finished, s.Failed)
+	fmt.Fprintf(&sb, "%-12s %10s %10s %10s\n", "", "mean", "min", "max")
+	for _, row := range []struct {
+		name string
+		stat Stat
+	}{
+		{"score", s.Score},
+		{"turns", s.Turns},
+		{"deaths", s.Deaths},
+		{"treasures", s.Treasures},
+		{"locations", s.Locations},
+		{"invalid", s.Invalid},
+		{"stuck", s.Stuck},
+		{"fallbacks", s.Fallbacks},
+		{"reward", s.Reward},
+		{"latency ms", s.LatencyMs},
+	} {
+		fmt.Fprintf(&sb, "%-12s %10.1f %10.1f %10.1f\n", row.name, row.stat.Mean, row.stat.Min, row.stat.Max)
+	}
+	return sb.String()
+}
+
+// Write writes the results in a format: csv, a row per game, or json, the
+// games with their summary.
+func Write(w io.Writer, format string, results []Result) error {
+	switch format {
+	case "csv":
+		return WriteCSV(w, results)
+	case "json":
+		return WriteJSON(w, results)
+	default:
+		return fmt.Errorf("unknown report format %q (want csv or json)", format)
+	}
+}
+
+// csvHeader names the CSV columns, which follow the fields of Result.
+var csvHeader = []string{"game", "seed", "score", "turns", "commands", "deaths", "treasures", "locations", "invalid", "stuck", "fallbacks", "reward", "finished", "latency_mean_ms", "latency_max_ms", "error"}
+
+// WriteCSV writes a row per game, after a header.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		row := []string{
+			strconv.Itoa(r.Game),
+			strconv.Itoa(r.Seed),
+			strconv.Itoa(r.Score),
+			strconv.Itoa(r.Turns),
+			strconv.Itoa(r.Commands),
+			strconv.Itoa(r.Deaths),
+			strconv.Itoa(r.Treasures),
+			strconv.Itoa(r.Locations),
+			strconv.Itoa(r.Invalid),
+			strconv.Itoa(r.Stuck),
+			strconv.Itoa(r.Fallbacks),
+			strconv.FormatFloat(r.Reward, 'f', 1, 64),
+			strconv.FormatBool(r.Finished),
+			strconv.FormatFloat(r.LatencyMeanMs, 'f', 1, 64),
+			strconv.FormatFloat(r.LatencyMaxMs, 'f', 1, 64),
+			r.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the games and their summary as indented JSON.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Report{Games: results, Summary: Summarise(results)})
+}
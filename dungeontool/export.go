@@ -0,0 +1,377 @@
+package dungeontool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// Location flags exported for each location, in the order of the COND_ bits.
+var conditionNames = []struct {
+	Bit  int
+	Name string
+}{
+	{dungeon.COND_LIT, "lit"},
+	{dungeon.COND_OILY, "oily"},
+	{dungeon.COND_FLUID, "fluid"},
+	{dungeon.COND_NOARRR, "noarrr"},
+	{dungeon.COND_NOBACK, "noback"},
+	{dungeon.COND_ABOVE, "above"},
+	{dungeon.COND_DEEP, "deep"},
+	{dungeon.COND_FOREST, "forest"},
+	{dungeon.COND_FORCED, "forced"},
+	{dungeon.COND_ALLDIFFERENT, "maze_alldifferent"},
+	{dungeon.COND_ALLALIKE, "maze_allalike"},
+}
+
+// specialNames describes the special travel destinations handled in PlayerMove.
+var specialNames = map[int]string{
+	1: "Plover-alcove passage",
+	2: "Plover transport",
+	3: "Troll bridge",
+}
+
+// Graph is the complete dungeon as locations joined by travel edges.
+type Graph struct {
+	Locations []Location `json:"locations"`
+	Edges     []Edge     `json:"edges"`
+}
+
+// Location is a dungeon location and its condition flags.
+type Location struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Conditions  []string `json:"conditions"`
+}
+
+// Edge is a travel rule out of a location. Consecutive travel entries that
+// share a condition and destination are merged, so one edge can have several
+// motion words.
+type Edge struct {
+	From        int         `json:"from"`
+	Motions     []string    `json:"motions"`
+	Condition   Condition   `json:"condition"`
+	Destination Destination `json:"destination"`
+	NoDwarves   bool        `json:"nodwarves,omitempty"`
+}
+
+// Condition is the test a travel rule must pass before it is taken.
+type Condition struct {
+	Type    string `json:"type"` // "always", "pct", "carry", "with" or "not"
+	Percent int    `json:"percent,omitempty"`
+	Object  string `json:"object,omitempty"`
+	State   *int64 `json:"state,omitempty"` // Set only for "not", where 0 is a state
+}
+
+// Destination is where a travel rule leads.
+type Destination struct {
+	Type     string `json:"type"` // "goto", "special" or "speak"
+	Location int    `json:"location,omitempty"`
+	Special  int    `json:"special,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Build walks the compiled dungeon tables and returns the full graph.
+func Build() Graph {
+	var g Graph
+
+	for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+		g.Locations = append(g.Locations, Location{
+			ID:          loc,
+			Name:        LocationName(loc),
+			Description: dungeon.Locations[loc].Description.Small,
+			Conditions:  LocationConditions(loc),
+		})
+
+		for _, rule := range TravelRules(loc) {
+			g.Edges = append(g.Edges, edgeFor(loc, rule))
+		}
+	}
+
+	return g
+}
+
+// TravelRules returns the travel entries for a location, grouped so that
+// entries sharing a condition and destination are together.
+func TravelRules(loc int) [][]dungeon.Travelop_t {
+	if loc < 0 || loc >= len(dungeon.TKey) {
+		return nil
+	}
+
+	var rules [][]dungeon.Travelop_t
+	for i := int(dungeon.TKey[loc]); i < len(dungeon.Travel); i++ {
+		entry := dungeon.Travel[i]
+		if n := len(rules); n > 0 && sameRule(rules[n-1][0], entry) {
+			rules[n-1] = append(rules[n-1], entry)
+		} else {
+			rules = append(rules, []dungeon.Travelop_t{entry})
+		}
+		if entry.Stop {
+			break
+		}
+	}
+
+	return rules
+}
+
+// sameRule reports whether two travel entries differ only in their motion.
+func sameRule(a, b dungeon.Travelop_t) bool {
+	return a.CondType == b.CondType && a.CondArg1 == b.CondArg1 && a.CondArg2 == b.CondArg2 &&
+		a.DestType == b.DestType && a.DestVal == b.DestVal && a.NoDwarves == b.NoDwarves
+}
+
+func edgeFor(from int, rule []dungeon.Travelop_t) Edge {
+	first := rule[0]
+
+	edge := Edge{
+		From:      from,
+		NoDwarves: first.NoDwarves,
+		Condition: Condition{Type: "always"},
+	}
+	for _, entry := range rule {
+		edge.Motions = append(edge.Motions, MotionName(entry.Motion))
+	}
+
+	switch first.CondType {
+	case dungeon.CondPct:
+		edge.Condition = Condition{Type: "pct", Percent: first.CondArg1}
+	case dungeon.CondCarry:
+		edge.Condition = Condition{Type: "carry", Object: ObjectName(first.CondArg1)}
+	case dungeon.CondWith:
+		edge.Condition = Condition{Type: "with", Object: ObjectName(first.CondArg1)}
+	case dungeon.CondNot:
+		edge.Condition = Condition{Type: "not", Object: ObjectName(first.CondArg1), State: &first.CondArg2}
+	}
+
+	switch first.DestType {
+	case dungeon.DestGoto:
+		edge.Destination = Destination{Type: "goto", Location: first.DestVal, Name: LocationName(first.DestVal)}
+	case dungeon.DestSpecial:
+		edge.Destination = Destination{Type: "special", Special: first.DestVal, Name: specialNames[first.DestVal]}
+	case dungeon.DestSpeak:
+		edge.Destination = Destination{Type: "speak", Message: message(first.DestVal)}
+	}
+
+	return edge
+}
+
+// LocationName returns a short name for a location, taken from its short
+// description or the first sentence of its long one.
+func LocationName(loc int) string {
+	if loc <= 0 || loc >= len(dungeon.Locations) {
+		return "Nowhere"
+	}
+
+	name := dungeon.Locations[loc].Description.Small
+	if name == "" {
+		name = dungeon.Locations[loc].Description.Big
+		if i := strings.IndexAny(name, ".\n"); i >= 0 {
+			name = name[:i]
+		}
+	}
+	if name == "" {
+		name = fmt.Sprintf("Location %d", loc)
+	}
+
+	return name
+}
+
+// LocationConditions returns the names of the condition flags set for a location.
+func LocationConditions(loc int) []string {
+	conds := []string{}
+	if loc < 0 || loc >= len(dungeon.Conditions) {
+		return conds
+	}
+
+	for _, c := range conditionNames {
+		if dungeon.Conditions[loc]&(1<<c.Bit) != 0 {
+			conds = append(conds, c.Name)
+		}
+	}
+	return conds
+}
+
+// MotionName returns the first vocabulary word for a motion.
+func MotionName(motion int) string {
+	if motion >= 0 && motion < len(dungeon.Motions) {
+		if strs := dungeon.Motions[motion].Words.Strs; len(strs) > 0 && strs[0] != "" {
+			return strings.ToUpper(strs[0])
+		}
+	}
+	if motion == 1 {
+		return "(forced)"
+	}
+	return fmt.Sprintf("motion %d", motion)
+}
+
+// ObjectName returns the first vocabulary word for an object.
+func ObjectName(obj int) string {
+	if obj > 0 && obj < len(dungeon.Objects) {
+		if strs := dungeon.Objects[obj].Words.Strs; len(strs) > 0 && strs[0] != "" {
+			return strings.ToUpper(strs[0])
+		}
+	}
+	return fmt.Sprintf("object %d", obj)
+}
+
+func message(msg int) string {
+	if msg < 0 || msg >= len(dungeon.Arbitrary_Messages) {
+		return fmt.Sprintf("message %d", msg)
+	}
+	return dungeon.Arbitrary_Messages[msg]
+}
+
+// Export writes the dungeon graph in the given format, "dot" or "json".
+func Export(w io.Writer, format string) error {
+	g := Build()
+
+	switch format {
+	case "json":
+		return g.WriteJSON(w)
+	case "dot":
+		return g.WriteDot(w)
+	default:
+		return fmt.Errorf("unknown export format %q (want dot or json)", format)
+	}
+}
+
+// WriteJSON writes the graph as indented JSON.
+func (g Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// WriteDot writes the graph in Graphviz dot format. Dark locations are
+// shaded, forest locations green and maze locations boxed. Special travel
+// gets its own diamond node and speak rules are drawn as dashed loops.
+func (g Graph) WriteDot(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph dungeon {\n")
+	b.WriteString("  node [shape=ellipse, fontsize=10];\n")
+	b.WriteString("  edge [fontsize=8];\n")
+
+	for _, loc := range g.Locations {
+		attrs := []string{fmt.Sprintf("label=%s", dotQuote(fmt.Sprintf("%d: %s", loc.ID, loc.Name)))}
+
+		var styles []string
+		color := ""
+		for _, c := range loc.Conditions {
+			switch c {
+			case "forest":
+				color = "palegreen"
+			case "maze_allalike", "maze_alldifferent":
+				attrs = append(attrs, "shape=box")
+			}
+		}
+		if !contains(loc.Conditions, "lit") {
+			color = "gray80"
+		}
+		if color != "" {
+			styles = append(styles, "filled")
+			attrs = append(attrs, "fillcolor="+color)
+		}
+		if contains(loc.Conditions, "deep") {
+			styles = append(styles, "bold")
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, fmt.Sprintf("style=%q", strings.Join(styles, ",")))
+		}
+
+		fmt.Fprintf(&b, "  loc%d [%s];\n", loc.ID, strings.Join(attrs, ", "))
+	}
+
+	specials := make([]int, 0, len(specialNames))
+	for n := range specialNames {
+		specials = append(specials, n)
+	}
+	sort.Ints(specials)
+	for _, n := range specials {
+		fmt.Fprintf(&b, "  special%d [shape=diamond, label=%s];\n", n, dotQuote(specialNames[n]))
+	}
+
+	for _, e := range g.Edges {
+		label := strings.Join(e.Motions, " ")
+		if cond := e.Condition.String(); cond != "" {
+			label += "\n[" + cond + "]"
+		}
+
+		var attrs []string
+		target := ""
+		switch e.Destination.Type {
+		case "goto":
+			target = fmt.Sprintf("loc%d", e.Destination.Location)
+		case "special":
+			target = fmt.Sprintf("special%d", e.Destination.Special)
+			attrs = append(attrs, "color=blue")
+		case "speak":
+			target = fmt.Sprintf("loc%d", e.From)
+			label += "\n\"" + truncate(firstLine(e.Destination.Message), 40) + "\""
+			attrs = append(attrs, "style=dashed", "color=gray50")
+		}
+		if e.Condition.Type != "always" {
+			attrs = append(attrs, "fontcolor=red")
+		}
+		attrs = append([]string{"label=" + dotQuote(label)}, attrs...)
+
+		fmt.Fprintf(&b, "  loc%d -> %s [%s];\n", e.From, target, strings.Join(attrs, ", "))
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String describes a condition briefly, or returns "" for unconditional travel.
+func (c Condition) String() string {
+	switch c.Type {
+	case "pct":
+		return fmt.Sprintf("%d%%", c.Percent)
+	case "carry":
+		return "carrying " + c.Object
+	case "with":
+		return "with " + c.Object
+	case "not":
+		return fmt.Sprintf("%s not in state %d", c.Object, *c.State)
+	}
+	return ""
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return "\"" + s + "\""
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package dungeontool
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestBuildLocations tests that every location is exported with its flags
+func TestBuildLocations(t *testing.T) {
+	g := Build()
+
+	if len(g.Locations) != dungeon.NLOCATIONS {
+		t.Fatalf("Locations: got %d, want %d", len(g.Locations), dungeon.NLOCATIONS)
+	}
+
+	start := g.Locations[dungeon.LOC_START-1]
+	if start.ID != dungeon.LOC_START || !contains(start.Conditions, "lit") {
+		t.Errorf("Unexpected start location: %+v", start)
+	}
+
+	var forest, maze bool
+	for _, loc := range g.Locations {
+		forest = forest || contains(loc.Conditions, "forest")
+		maze = maze || contains(loc.Conditions, "maze_allalike")
+	}
+	if !forest || !maze {
+		t.Errorf("Expected forest and maze locations (forest %v, maze %v)", forest, maze)
+	}
+}
+
+// TestBuildEdges tests that the travel table is turned into edges
+func TestBuildEdges(t *testing.T) {
+	g := Build()
+
+	var sawPct, sawCarry, sawNot, sawSpecial, sawSpeak, sawWest bool
+	for _, e := range g.Edges {
+		switch e.Condition.Type {
+		case "pct":
+			sawPct = e.Condition.Percent > 0
+		case "carry":
+			sawCarry = e.Condition.Object != ""
+		case "not":
+			sawNot = true
+		}
+		switch e.Destination.Type {
+		case "special":
+			sawSpecial = sawSpecial || e.Destination.Name != ""
+		case "speak":
+			sawSpeak = sawSpeak || e.Destination.Message != ""
+		}
+		if e.From == dungeon.LOC_START && e.Destination.Location == dungeon.LOC_HILL && contains(e.Motions, "WEST") {
+			sawWest = true
+		}
+	}
+
+	if !sawPct || !sawCarry || !sawNot || !sawSpecial || !sawSpeak {
+		t.Errorf("Missing edge kinds: pct %v, carry %v, not %v, special %v, speak %v",
+			sawPct, sawCarry, sawNot, sawSpecial, sawSpeak)
+	}
+	if !sawWest {
+		t.Error("Expected WEST from the start to lead to the hill")
+	}
+}
+
+// TestTravelRulesGroupMotions tests that entries sharing a destination are merged
+func TestTravelRulesGroupMotions(t *testing.T) {
+	total := 0
+	for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+		for _, rule := range TravelRules(loc) {
+			total += len(rule)
+		}
+	}
+
+	// Every travel entry except the dummy one for LOC_NOWHERE belongs to a rule
+	if total != dungeon.NTRAVEL-1 {
+		t.Errorf("Travel entries covered: got %d, want %d", total, dungeon.NTRAVEL-1)
+	}
+}
+
+// TestExportJSON tests that the JSON export round-trips
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, "json"); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+
+	var g Graph
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(g.Locations) != dungeon.NLOCATIONS || len(g.Edges) == 0 {
+		t.Errorf("Unexpected graph: %d locations, %d edges", len(g.Locations), len(g.Edges))
+	}
+}
+
+// TestExportDot tests the Graphviz output
+func TestExportDot(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, "dot"); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"digraph dungeon {", "loc1 [", "loc1 -> loc2", "special3 [shape=diamond", "style=dashed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in dot output", want)
+		}
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Error("dot output should end with a closing brace")
+	}
+}
+
+// TestExportUnknownFormat tests that an unknown format is an error
+func TestExportUnknownFormat(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// TestExportKeepsZeroState tests that a "not OBJ 0" rule keeps its state through JSON
+func TestExportKeepsZeroState(t *testing.T) {
+	var rule *Edge
+	for _, edge := range Build().Edges {
+		if edge.Condition.Type == "not" && edge.Condition.State != nil && *edge.Condition.State == 0 {
+			rule = &edge
+			break
+		}
+	}
+	if rule == nil {
+		t.Fatal("Expected a rule conditional on an object not being in state 0")
+	}
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	var got Edge
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if got.Condition.State == nil || *got.Condition.State != 0 || got.Condition.Object != rule.Condition.Object {
+		t.Errorf("Expected %s not in state 0 to survive, got %s", rule.Condition.Object, data)
+	}
+
+	// Other rules have no state to give
+	data, err = json.Marshal(Condition{Type: "always"})
+	if err != nil || strings.Contains(string(data), "state") {
+		t.Errorf("Expected no state in %s, %v", data, err)
+	}
+}
@@ -0,0 +1,390 @@
+package dungeontool
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// Issue is a problem found in the dungeon definition.
+type Issue struct {
+	Check   string // Name of the check that found the problem
+	Subject string // The location, object, message or hint concerned
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Check, i.Subject, i.Message)
+}
+
+// LintOptions controls the checks made by Lint.
+type LintOptions struct {
+	// SourceDir is the root of the goAdventure source tree. The unused message
+	// check scans the engine sources there for message references and is
+	// skipped when SourceDir is empty.
+	SourceDir string
+}
+
+// engineLocations are locations the engine moves the player to directly
+// rather than through the travel table: closing the cave puts the player in
+// the repository, and the rug flies the player to the ledge.
+var engineLocations = []int{dungeon.LOC_NE, dungeon.LOC_SW, dungeon.LOC_LEDGE}
+
+// unportedMessages are spoken by the original engine for things this one
+// does its own way, such as saving, resuming and its questions, so they are
+// expected to go unused.
+var unportedMessages = map[string]bool{
+	"NOT_LOCKABLE":        true,
+	"NEARBY":              true,
+	"NOT_KNOWHOW":         true,
+	"PLEASE_ANSWER":       true,
+	"CLUE_QUERY":          true,
+	"FOREST_QUERY":        true,
+	"SAVERESUME_DISABLED": true,
+	"RESUME_HELP":         true,
+	"BAD_SAVE":            true,
+	"SAVE_TAMPERING":      true,
+	"NUMERIC_REQUIRED":    true,
+}
+
+// Lint checks the compiled dungeon tables for mistakes that are easy to make
+// when editing adventure.yaml.
+func Lint(opts LintOptions) ([]Issue, error) {
+	var issues []Issue
+
+	issues = append(issues, checkTravelTargets()...)
+	issues = append(issues, checkReachable()...)
+	issues = append(issues, checkDeadEnds()...)
+	issues = append(issues, checkObjects()...)
+	issues = append(issues, checkHints()...)
+
+	if opts.SourceDir != "" {
+		unused, err := checkMessages(opts.SourceDir)
+		if err != nil {
+			return issues, err
+		}
+		issues = append(issues, unused...)
+	}
+
+	return issues, nil
+}
+
+// checkTravelTargets reports travel rules whose condition or destination
+// refers to something that doesn't exist.
+func checkTravelTargets() []Issue {
+	var issues []Issue
+
+	for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+		subject := locationSubject(loc)
+
+		for _, rule := range TravelRules(loc) {
+			t := rule[0]
+			words := strings.Join(motionNames(rule), " ")
+
+			switch t.CondType {
+			case dungeon.CondCarry, dungeon.CondWith, dungeon.CondNot:
+				if t.CondArg1 <= 0 || t.CondArg1 > dungeon.NOBJECTS {
+					issues = append(issues, Issue{"travel", subject, fmt.Sprintf("%s: condition refers to nonexistent object %d", words, t.CondArg1)})
+				}
+			case dungeon.CondPct:
+				if t.CondArg1 <= 0 || t.CondArg1 >= 100 {
+					issues = append(issues, Issue{"travel", subject, fmt.Sprintf("%s: percentage %d out of range", words, t.CondArg1)})
+				}
+			}
+
+			switch t.DestType {
+			case dungeon.DestGoto:
+				// LOC_NOWHERE is a legitimate destination, it kills the player
+				if t.DestVal < 0 || t.DestVal > dungeon.NLOCATIONS {
+					issues = append(issues, Issue{"travel", subject, fmt.Sprintf("%s: goes to nonexistent location %d", words, t.DestVal)})
+				}
+			case dungeon.DestSpecial:
+				if _, ok := specialNames[t.DestVal]; !ok {
+					issues = append(issues, Issue{"travel", subject, fmt.Sprintf("%s: unknown special travel %d", words, t.DestVal)})
+				}
+			case dungeon.DestSpeak:
+				if t.DestVal <= 0 || t.DestVal >= len(dungeon.Arbitrary_Messages) {
+					issues = append(issues, Issue{"travel", subject, fmt.Sprintf("%s: speaks nonexistent message %d", words, t.DestVal)})
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+// checkReachable reports locations that can't be reached from the start.
+func checkReachable() []Issue {
+	reached := make(map[int]bool)
+	queue := append([]int{dungeon.LOC_START}, engineLocations...)
+	for _, loc := range queue {
+		reached[loc] = true
+	}
+
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+
+		for _, next := range destinations(loc) {
+			if next > 0 && next <= dungeon.NLOCATIONS && !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	var issues []Issue
+	for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+		if !reached[loc] {
+			issues = append(issues, Issue{"unreachable", locationSubject(loc), "no travel rule leads here from the start"})
+		}
+	}
+	return issues
+}
+
+// checkDeadEnds reports rooms with no way out. Forced rooms move the player
+// on automatically so they are expected to have a single exit.
+func checkDeadEnds() []Issue {
+	var issues []Issue
+
+	for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+		if dungeon.Conditions[loc]&(1<<dungeon.COND_FORCED) != 0 {
+			continue
+		}
+
+		exits := 0
+		for _, next := range destinations(loc) {
+			if next != loc {
+				exits++
+			}
+		}
+		if exits == 0 {
+			issues = append(issues, Issue{"dead-end", locationSubject(loc), "no travel rule leads anywhere else"})
+		}
+	}
+
+	return issues
+}
+
+// destinations returns the locations a travel rule from loc can lead to,
+// following the special travel cases.
+func destinations(loc int) []int {
+	var dests []int
+
+	for _, rule := range TravelRules(loc) {
+		t := rule[0]
+		switch t.DestType {
+		case dungeon.DestGoto:
+			dests = append(dests, t.DestVal)
+		case dungeon.DestSpecial:
+			switch t.DestVal {
+			case 1:
+				// Plover-alcove passage goes between the two
+				dests = append(dests, dungeon.LOC_PLOVER, dungeon.LOC_ALCOVE)
+			case 3:
+				// Troll bridge goes to the other side of the chasm
+				troll := dungeon.Objects[dungeon.TROLL]
+				dests = append(dests, troll.Plac+troll.Fixd-loc)
+			}
+			// Plover transport falls through to the next rule
+		}
+	}
+
+	return dests
+}
+
+// checkObjects reports objects the player can carry without inventory text,
+// and objects without a message for each of their states. States are counted
+// from an object's descriptions and change messages; sounds can come several
+// to a state, as the bird's do, so only a shorter list of them is reported.
+func checkObjects() []Issue {
+	return checkObjectList(dungeon.Objects)
+}
+
+func checkObjectList(objects []dungeon.Object_t) []Issue {
+	var issues []Issue
+
+	for obj := 1; obj < len(objects); obj++ {
+		o := objects[obj]
+		subject := fmt.Sprintf("object %d (%s)", obj, ObjectName(obj))
+
+		// Fixed objects, and ones never placed, are never in the inventory
+		carriable := o.Fixd == 0 && o.Plac != dungeon.LOC_NOWHERE
+		if o.Inventory == "" && carriable {
+			issues = append(issues, Issue{"object", subject, "has no inventory text"})
+		}
+
+		lists := []struct {
+			name  string
+			texts []string
+		}{{"descriptions", o.Descriptions}, {"change messages", o.Changes}, {"sound messages", o.Sounds}, {"text messages", o.Texts}}
+
+		// Objects without descriptions are covered by their location's text
+		if stateCount(o.Descriptions) == 0 {
+			continue
+		}
+
+		states := max(stateCount(o.Descriptions), stateCount(o.Changes))
+		for _, l := range lists {
+			// An unused message list is fine, a partial one isn't
+			if n := stateCount(l.texts); n > 0 && n < states {
+				issues = append(issues, Issue{"object", subject, fmt.Sprintf("has %d states but only %d %s", states, n, l.name)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// stateCount returns the number of states a list of per-state texts covers.
+// The generator writes a single empty string for a missing list.
+func stateCount(texts []string) int {
+	if len(texts) == 1 && texts[0] == "" {
+		return 0
+	}
+	return len(texts)
+}
+
+// checkHints reports hints that can never be offered because no location is
+// marked for them, or they have no question or hint text.
+func checkHints() []Issue {
+	var issues []Issue
+
+	for hint := 0; hint < dungeon.NHINTS; hint++ {
+		h := dungeon.Hints[hint]
+		subject := fmt.Sprintf("hint %d", hint+1)
+
+		bit := int32(1) << (hint + 1 + dungeon.COND_HBASE)
+		marked := false
+		for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+			if dungeon.Conditions[loc]&bit != 0 {
+				marked = true
+				break
+			}
+		}
+
+		switch {
+		case !marked:
+			issues = append(issues, Issue{"hint", subject, "no location has its condition bit set"})
+		case h.Turns <= 0:
+			issues = append(issues, Issue{"hint", subject, fmt.Sprintf("needs %d turns to fire", h.Turns)})
+		case h.Question == "" || h.Hint == "":
+			issues = append(issues, Issue{"hint", subject, "has no question or hint text"})
+		}
+	}
+
+	return issues
+}
+
+// checkMessages reports arbitrary messages that nothing refers to. Messages
+// are used by the travel table, location sounds and the engine itself, which
+// is found by scanning the advent package sources under sourceDir.
+func checkMessages(sourceDir string) ([]Issue, error) {
+	names, err := messageNames(filepath.Join(sourceDir, "dungeon", "dungeonTypes.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[int]bool)
+	for loc := 1; loc <= dungeon.NLOCATIONS; loc++ {
+		used[dungeon.Locations[loc].Sound] = true
+		for _, rule := range TravelRules(loc) {
+			if rule[0].DestType == dungeon.DestSpeak {
+				used[rule[0].DestVal] = true
+			}
+		}
+	}
+
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	files, err := filepath.Glob(filepath.Join(sourceDir, "advent", "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no engine sources found in %s", filepath.Join(sourceDir, "advent"))
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "dungeon" {
+					if i, ok := index[sel.Sel.Name]; ok {
+						used[i] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	var issues []Issue
+	for i := 1; i < len(names) && i < len(dungeon.Arbitrary_Messages); i++ {
+		if !used[i] && !unportedMessages[names[i]] {
+			issues = append(issues, Issue{"message", fmt.Sprintf("message %d (%s)", i, names[i]), "is never used"})
+		}
+	}
+	return issues, nil
+}
+
+// messageNames reads the arbitrary message constant names, in order, from the
+// generated dungeon types.
+func messageNames(path string) ([]string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST || len(gen.Specs) == 0 {
+			continue
+		}
+		first := gen.Specs[0].(*ast.ValueSpec)
+		if first.Names[0].Name != "NO_MESSAGE" {
+			continue
+		}
+
+		names := make([]string, 0, len(gen.Specs))
+		for _, spec := range gen.Specs {
+			names = append(names, spec.(*ast.ValueSpec).Names[0].Name)
+		}
+		return names, nil
+	}
+
+	return nil, fmt.Errorf("no arbitrary message constants found in %s", path)
+}
+
+func motionNames(rule []dungeon.Travelop_t) []string {
+	words := make([]string, 0, len(rule))
+	for _, t := range rule {
+		words = append(words, MotionName(t.Motion))
+	}
+	return words
+}
+
+func locationSubject(loc int) string {
+	return fmt.Sprintf("location %d (%s)", loc, LocationName(loc))
+}
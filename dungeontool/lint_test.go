@@ -0,0 +1,140 @@
+package dungeontool
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewsjg/goAdventure/dungeon"
+)
+
+// TestCheckTravelTargets tests that the shipped travel table is consistent
+func TestCheckTravelTargets(t *testing.T) {
+	if issues := checkTravelTargets(); len(issues) != 0 {
+		t.Errorf("Unexpected travel issues: %v", issues)
+	}
+}
+
+// TestCheckReachable tests that only locations with no way in are reported
+func TestCheckReachable(t *testing.T) {
+	for _, issue := range checkReachable() {
+		for _, loc := range []int{dungeon.LOC_START, dungeon.LOC_BUILDING, dungeon.LOC_NE, dungeon.LOC_PLOVER, dungeon.LOC_ALCOVE} {
+			if issue.Subject == locationSubject(loc) {
+				t.Errorf("Location %d should be reachable", loc)
+			}
+		}
+	}
+}
+
+// TestDestinationsSpecial tests that special travel is followed
+func TestDestinationsSpecial(t *testing.T) {
+	troll := dungeon.Objects[dungeon.TROLL]
+
+	dests := destinations(troll.Plac)
+	found := false
+	for _, d := range dests {
+		found = found || d == troll.Fixd
+	}
+	if !found {
+		t.Errorf("Expected the troll bridge to lead across the chasm, got %v", dests)
+	}
+}
+
+// TestCheckHints tests that every hint can fire in the shipped dungeon
+func TestCheckHints(t *testing.T) {
+	if issues := checkHints(); len(issues) != 0 {
+		t.Errorf("Unexpected hint issues: %v", issues)
+	}
+}
+
+// TestStateCount tests that the generator's placeholder list counts as empty
+func TestStateCount(t *testing.T) {
+	if n := stateCount([]string{""}); n != 0 {
+		t.Errorf("stateCount of placeholder: got %d, want 0", n)
+	}
+	if n := stateCount([]string{"", "Open"}); n != 2 {
+		t.Errorf("stateCount: got %d, want 2", n)
+	}
+}
+
+// TestCheckMessages tests the unused message check against the engine sources
+func TestCheckMessages(t *testing.T) {
+	names, err := messageNames("../dungeon/dungeonTypes.go")
+	if err != nil {
+		t.Fatalf("messageNames returned error: %v", err)
+	}
+	if names[0] != "NO_MESSAGE" || names[dungeon.CAVE_NEARBY] != "CAVE_NEARBY" {
+		t.Errorf("Unexpected message names: %v", names[:3])
+	}
+
+	issues, err := checkMessages("..")
+	if err != nil {
+		t.Fatalf("checkMessages returned error: %v", err)
+	}
+	for _, issue := range issues {
+		// Used by the engine and by the travel table respectively
+		if strings.Contains(issue.Subject, "(DONT_KNOW)") || strings.Contains(issue.Subject, "(WHICH_WAY)") {
+			t.Errorf("Message reported unused: %v", issue)
+		}
+	}
+}
+
+// TestLintBadSourceDir tests that a missing source tree is an error
+func TestLintBadSourceDir(t *testing.T) {
+	if _, err := Lint(LintOptions{SourceDir: t.TempDir()}); err == nil {
+		t.Error("Expected error for a directory without sources")
+	}
+}
+
+// TestLintShippedDungeon tests that the shipped dungeon and engine lint clean
+func TestLintShippedDungeon(t *testing.T) {
+	issues, err := Lint(LintOptions{SourceDir: ".."})
+	if err != nil {
+		t.Fatalf("Lint returned error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Unexpected issues: %v", issues)
+	}
+}
+
+// TestCheckReachableEngineMoves tests that places the engine moves the
+// player to count as reachable
+func TestCheckReachableEngineMoves(t *testing.T) {
+	for _, issue := range checkReachable() {
+		if issue.Subject == locationSubject(dungeon.LOC_LEDGE) {
+			t.Error("The ledge should be reachable by the rug")
+		}
+	}
+}
+
+// TestCheckDeadEnds tests that every unforced room has a way out
+func TestCheckDeadEnds(t *testing.T) {
+	if issues := checkDeadEnds(); len(issues) != 0 {
+		t.Errorf("Unexpected dead ends: %v", issues)
+	}
+}
+
+// TestCheckObjects tests the inventory text and state checks
+func TestCheckObjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		object dungeon.Object_t
+		issues int
+	}{
+		{"carriable without inventory", dungeon.Object_t{Plac: dungeon.LOC_START, Descriptions: []string{""}}, 1},
+		{"fixed without inventory", dungeon.Object_t{Plac: dungeon.LOC_START, Fixd: -1, Descriptions: []string{""}}, 0},
+		{"never placed without inventory", dungeon.Object_t{Descriptions: []string{""}}, 0},
+		{"two sounds a state", dungeon.Object_t{Inventory: "Bird", Plac: dungeon.LOC_START,
+			Descriptions: []string{"Free", "Caged"}, Sounds: []string{"Sing", "Tweet", "Hush", "Hush"}}, 0},
+		{"missing a description", dungeon.Object_t{Inventory: "Door", Plac: dungeon.LOC_START, Fixd: -1,
+			Descriptions: []string{"Shut"}, Changes: []string{"It shuts", "It opens"}}, 1},
+		{"missing a sound", dungeon.Object_t{Inventory: "Bell", Plac: dungeon.LOC_START,
+			Descriptions: []string{"Still", "Ringing"}, Sounds: []string{"Silence"}}, 1},
+	}
+
+	for _, tt := range tests {
+		objects := []dungeon.Object_t{{}, tt.object}
+		if issues := checkObjectList(objects); len(issues) != tt.issues {
+			t.Errorf("%s: got %v, want %d issue(s)", tt.name, issues, tt.issues)
+		}
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/andrewsjg/goAdventure/advent"
+	"github.com/andrewsjg/goAdventure/bench"
+	"github.com/andrewsjg/goAdventure/dungeontool"
 	"github.com/andrewsjg/goAdventure/ollama"
 	"github.com/andrewsjg/goAdventure/telemetry"
 	"github.com/andrewsjg/goAdventure/tui"
@@ -17,6 +22,16 @@ import (
 
 func main() {
 
+	// Subcommands for working on the dungeon rather than playing it
+	if len(os.Args) > 1 && os.Args[1] == "dungeon" {
+		os.Exit(runDungeonCommand(os.Args[2:]))
+	}
+
+	// Headless AI games for comparing models and prompts
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(runBenchCommand(os.Args[2:]))
+	}
+
 	// TODO: Logs
 
 	logFileName := ""
@@ -29,15 +44,29 @@ func main() {
 	noTUI := false
 	enableTracing := false
 	tracingEndpoint := ""
+	undoDepth := advent.UNDO_DEPTH
+	noUndo := false
+	autoCorrect := false
+	noNormalise := false
+	triedExits := false
+	lang := ""
+	seed := 0
 
 	// AI player flags
 	aiMode := false
 	aiModel := "qwen2.5:7b"
 	ollamaURL := "http://localhost:11434"
+	aiBackend := ollama.BackendOllama
+	aiURL := ""
+	aiScript := ""
+	aiCassette := ""
 	aiThinking := false
 	aiDelay := 1000
 	aiTimeout := 120
+	aiRetries := ollama.DefaultRetryPolicy.MaxRetries
+	aiAttempts := 3
 	aiTemp := 0.1 // Low temperature for more deterministic responses
+	aiRewards := ""
 
 	flag.StringVar(&logFileName, "l", "", "Create a log file of your game named as specified")
 	flag.BoolVar(&oldStyle, "o", false, "'Oldstyle' mode (no prompt, no command editing, displays 'Initialising...')")
@@ -48,15 +77,29 @@ func main() {
 	flag.BoolVar(&noTUI, "notui", false, "Run without TUI (classic terminal mode)")
 	flag.BoolVar(&enableTracing, "trace", false, "Enable OpenTelemetry tracing (sends to localhost:4318 by default)")
 	flag.StringVar(&tracingEndpoint, "trace-endpoint", "", "OpenTelemetry OTLP endpoint (e.g., localhost:4318)")
+	flag.IntVar(&undoDepth, "undo-depth", advent.UNDO_DEPTH, "Number of turns that can be undone with UNDO")
+	flag.BoolVar(&noUndo, "noundo", false, "Disable UNDO ('purist' mode)")
+	flag.BoolVar(&autoCorrect, "autocorrect", false, "Replace misspelt words with the nearest known word")
+	flag.BoolVar(&triedExits, "tried-exits", false, "EXITS lists only the ways you have already gone (no spoilers)")
+	flag.StringVar(&lang, "lang", "", "Language of the game text: a locale file, a name in ./locales, or 'pseudo'")
+	flag.IntVar(&seed, "seed", 0, "Seed for the game's random numbers, so a game can be played again the same way (default random)")
+	flag.BoolVar(&noNormalise, "literal", false, "Parse commands exactly as typed, without rewriting everyday English ('purist' mode)")
 
 	// AI player flags
 	flag.BoolVar(&aiMode, "ai", false, "Enable AI player mode (uses Ollama)")
 	flag.StringVar(&aiModel, "model", "qwen2.5:7b", "Ollama model to use for AI player")
 	flag.StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "Ollama API URL")
+	flag.StringVar(&aiBackend, "ai-backend", ollama.BackendOllama, "AI backend: ollama, openai (any /v1/chat/completions server), fake or replay")
+	flag.StringVar(&aiURL, "ai-url", "", "Server URL for the AI backend (default -ollama-url for ollama, http://localhost:8080 for openai)")
+	flag.StringVar(&aiScript, "ai-script", "", "Responses for the fake AI backend, one per line")
+	flag.StringVar(&aiCassette, "ai-cassette", "", "Record the AI's replies to this file, or with -ai-backend replay, play them back from it")
 	flag.BoolVar(&aiThinking, "ai-thinking", false, "Show AI reasoning/thinking")
 	flag.IntVar(&aiDelay, "ai-delay", 1000, "Delay between AI moves in milliseconds")
 	flag.IntVar(&aiTimeout, "ai-timeout", 120, "Timeout for AI requests in seconds")
+	flag.IntVar(&aiRetries, "ai-retries", ollama.DefaultRetryPolicy.MaxRetries, "Times to retry an AI request after a server error or failed connection")
+	flag.IntVar(&aiAttempts, "ai-attempts", 3, "Tries the AI gets at a command the game understands before one is played anyway")
 	flag.Float64Var(&aiTemp, "ai-temp", 0.1, "AI temperature (0.0=deterministic, 1.0=creative)")
+	flag.StringVar(&aiRewards, "ai-rewards", "", "Reward weights for the AI, e.g. 'death=-50,turn=0' (default "+ollama.DefaultRewardWeights().String()+")")
 
 	// Parse the command-line flags
 	flag.Parse()
@@ -84,12 +127,37 @@ func main() {
 		fmt.Println("OpenTelemetry tracing enabled")
 	}
 
+	// Load the game text before the game starts talking. Oldstyle keeps the
+	// original English wording, so it can't be played in another language
+	if oldStyle && lang != "" && lang != "en" {
+		fmt.Println("Error: -o plays the original English text and can't be combined with -lang")
+		return
+	}
+	catalog, err := advent.LoadLocale(lang)
+	if err != nil {
+		fmt.Printf("Error loading language: %v\n", err)
+		return
+	}
+	locale := advent.NewLocale(catalog)
+	if err := locale.ValidateMessages(); err != nil {
+		fmt.Printf("Error in game text: %v\n", err)
+		return
+	}
+
 	// Initialize the game
 	if debug {
 		fmt.Println("Initializing game...")
 	}
 
-	game := advent.NewGame(0, restoreFileName, autoSaveFileName, logFileName, debug, oldStyle, autoSave, scripts)
+	game := advent.NewGame(seed, restoreFileName, autoSaveFileName, logFileName, debug, oldStyle, autoSave, scripts)
+	if catalog != nil {
+		game.UseLocale(locale)
+	}
+	game.Settings.UndoDepth = undoDepth
+	game.Settings.NoUndo = noUndo
+	game.Settings.AutoCorrect = autoCorrect
+	game.Settings.NoNormalise = noNormalise
+	game.Settings.TriedExitsOnly = triedExits
 
 	// Load script file if specified
 	if scriptFileName != "" {
@@ -121,15 +189,55 @@ func main() {
 	var aiPlayer *ollama.Player
 	var rewardTracker *ollama.RewardTracker
 	if aiMode {
-		client := ollama.NewClient(ollamaURL, aiModel, time.Duration(aiTimeout)*time.Second, aiTemp)
+		cfg := ollama.BackendConfig{
+			Backend:     aiBackend,
+			URL:         aiURL,
+			Model:       aiModel,
+			APIKey:      os.Getenv("OPENAI_API_KEY"),
+			Timeout:     time.Duration(aiTimeout) * time.Second,
+			Retries:     aiRetries,
+			Temperature: aiTemp,
+			Game:        game.Seedval,
+		}
+		if cfg.URL == "" && aiBackend == ollama.BackendOllama {
+			cfg.URL = ollamaURL
+		}
+		if aiScript != "" {
+			if cfg.Script, err = ollama.LoadScript(aiScript); err != nil {
+				fmt.Printf("Error loading AI script: %v\n", err)
+				return
+			}
+		}
+		if aiCassette != "" {
+			if cfg.Cassette, err = openCassette(aiCassette, aiBackend); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			defer cfg.Cassette.Close()
+		}
+
+		weights, err := ollama.ParseRewardWeights(aiRewards)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		client, err := ollama.NewLLM(cfg)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
 		aiPlayer = ollama.NewPlayer(client, aiThinking)
+		aiPlayer.SetValidator(game.Validate, aiAttempts)
 		rewardTracker = ollama.NewRewardTracker()
+		rewardTracker.Weights = weights
 		if debug {
-			fmt.Printf("AI player enabled using model: %s at %s (timeout: %ds, temp: %.2f)\n", aiModel, ollamaURL, aiTimeout, aiTemp)
+			fmt.Printf("AI player enabled using %s model: %s at %s (timeout: %ds, temp: %.2f)\n", aiBackend, aiModel, cfg.URL, aiTimeout, aiTemp)
 		}
 	}
 
-	if noTUI {
+	// Oldstyle emulates the original teletype interface, so there's no TUI
+	if noTUI || oldStyle {
 		// Run in classic terminal mode
 		runClassicMode(&game, aiPlayer, rewardTracker, aiThinking, aiDelay)
 	} else {
@@ -144,29 +252,230 @@ func main() {
 	}
 }
 
-// buildGameContext creates a GameContext from the current game state.
-func buildGameContext(game *advent.Game, rewardTracker *ollama.RewardTracker) *ollama.GameContext {
-	ctx := &ollama.GameContext{
-		GameOutput:      game.Output,
-		LocationDesc:    game.GetLocationDescription(),
-		VisibleObjects:  game.GetVisibleObjects(),
-		Inventory:       game.InventoryDescriptions(),
-		Score:           game.GetScore(),
-		Turns:           int(game.Turns),
-		Hints:           game.GenerateHints(),
-		ValidActions:    game.GetAllVerbs(),
-		ValidDirections: game.GetAllDirections(),
-		ValidObjects:    game.GetInteractableObjects(),
-	}
-	if rewardTracker != nil {
-		ctx.RewardFeedback = rewardTracker.GetFeedback()
-	}
-	return ctx
+// runDungeonCommand runs a "dungeon" subcommand and returns the exit status.
+func runDungeonCommand(args []string) int {
+	usage := "Usage: goAdventure dungeon export [-format dot|json] [-out file]\n       goAdventure dungeon lint [-src dir]"
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("dungeon export", flag.ContinueOnError)
+		format := fs.String("format", "dot", "Export format: dot or json")
+		outFile := fs.String("out", "", "Write the export to this file instead of stdout")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		out := os.Stdout
+		if *outFile != "" {
+			f, err := os.Create(*outFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *outFile, err)
+				return 1
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err := dungeontool.Export(out, *format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting dungeon: %v\n", err)
+			return 1
+		}
+		return 0
+
+	case "lint":
+		fs := flag.NewFlagSet("dungeon lint", flag.ContinueOnError)
+		src := fs.String("src", ".", "Root of the goAdventure source tree, used to find unused messages")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		// Without the engine sources there's no way to tell which messages it uses
+		opts := dungeontool.LintOptions{SourceDir: *src}
+		if _, err := os.Stat(filepath.Join(*src, "advent")); err != nil {
+			fmt.Fprintf(os.Stderr, "Engine sources not found in %s, skipping the unused message check\n", *src)
+			opts.SourceDir = ""
+		}
+
+		issues, err := dungeontool.Lint(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error linting dungeon: %v\n", err)
+			return 1
+		}
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) > 0 {
+			fmt.Fprintf(os.Stderr, "%d issue(s) found\n", len(issues))
+			return 1
+		}
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown dungeon command %q\n%s\n", args[0], usage)
+		return 2
+	}
+}
+
+// runBenchCommand plays AI games headlessly, writes a report of each game and
+// prints their summary, returning the exit status.
+func runBenchCommand(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	model := fs.String("model", "qwen2.5:7b", "Model to benchmark")
+	games := fs.Int("games", 10, "Games to play")
+	maxTurns := fs.Int("max-turns", 500, "Commands the AI may give before a game is stopped")
+	seedStart := fs.Int("seed-start", 1, "Seed of the first game; each later game uses the next seed")
+	parallel := fs.Int("parallel", 1, "Games to play at once")
+	format := fs.String("format", "csv", "Report format: csv or json")
+	outFile := fs.String("out", "", "Write the report to this file instead of stdout")
+	backend := fs.String("ai-backend", ollama.BackendOllama, "AI backend: ollama, openai, fake or replay")
+	url := fs.String("ai-url", "", "Server URL for the AI backend (default http://localhost:11434 for ollama, http://localhost:8080 for openai)")
+	script := fs.String("ai-script", "", "Responses for the fake AI backend, one per line")
+	cassette := fs.String("ai-cassette", "", "Record the AI's replies to this file, or with -ai-backend replay, play them back from it")
+	timeout := fs.Int("ai-timeout", 120, "Timeout for AI requests in seconds")
+	retries := fs.Int("ai-retries", ollama.DefaultRetryPolicy.MaxRetries, "Times to retry an AI request after a server error or failed connection")
+	attempts := fs.Int("ai-attempts", 3, "Tries the AI gets at a command the game understands before one is played anyway")
+	temp := fs.Float64("ai-temp", 0.1, "AI temperature (0.0=deterministic, 1.0=creative)")
+	rewards := fs.String("ai-rewards", "", "Reward weights for the AI, e.g. 'death=-50,turn=0' (default "+ollama.DefaultRewardWeights().String()+")")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	weights, err := ollama.ParseRewardWeights(*rewards)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	cfg := bench.Config{
+		Backend: ollama.BackendConfig{
+			Backend:     *backend,
+			URL:         *url,
+			Model:       *model,
+			APIKey:      os.Getenv("OPENAI_API_KEY"),
+			Timeout:     time.Duration(*timeout) * time.Second,
+			Retries:     *retries,
+			Temperature: *temp,
+		},
+		Games:     *games,
+		MaxTurns:  *maxTurns,
+		SeedStart: *seedStart,
+		Parallel:  *parallel,
+		Attempts:  *attempts,
+		Rewards:   weights,
+	}
+	if *script != "" {
+		var err error
+		if cfg.Backend.Script, err = ollama.LoadScript(*script); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading AI script: %v\n", err)
+			return 1
+		}
+	}
+	if *cassette != "" {
+		var err error
+		if cfg.Backend.Cassette, err = openCassette(*cassette, *backend); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer cfg.Backend.Cassette.Close()
+	}
+	// Check the backend and format before playing rather than after
+	if _, err := ollama.NewLLM(cfg.Backend); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	if err := bench.Write(io.Discard, *format, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	out := os.Stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *outFile, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Ctrl+C stops the games and reports those played so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results := bench.Run(ctx, cfg, func(r bench.Result) {
+		status := fmt.Sprintf("score %d in %d turns", r.Score, r.Turns)
+		if r.Error != "" {
+			status += ", failed: " + r.Error
+		}
+		fmt.Fprintf(os.Stderr, "Game %d/%d (seed %d): %s\n", r.Game, cfg.Games, r.Seed, status)
+	})
+
+	if err := bench.Write(out, *format, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		return 1
+	}
+	fmt.Fprint(os.Stderr, "\n"+bench.Summarise(results).String())
+	return 0
+}
+
+// openCassette loads a cassette to replay with the replay backend, or
+// creates one to record the other backends to.
+func openCassette(filename string, backend string) (*ollama.Cassette, error) {
+	if backend == ollama.BackendReplay {
+		return ollama.LoadCassette(filename)
+	}
+	return ollama.CreateCassette(filename)
+}
+
+// thinkingPrinter prints the AI's thinking as its reply streams in.
+type thinkingPrinter struct {
+	show    bool
+	prefix  string // Printed before the thinking
+	printed int    // Bytes of the thinking printed so far
+}
+
+// print prints what has arrived of the thinking since last time.
+func (t *thinkingPrinter) print(response string) {
+	thinking, _ := ollama.ParsePartial(response)
+	if !t.show || len(thinking) <= t.printed {
+		return
+	}
+	if t.printed == 0 {
+		fmt.Print(t.prefix + "[AI Thinking: ")
+	}
+	fmt.Print(thinking[t.printed:])
+	t.printed = len(thinking)
+}
+
+// done ends the thinking, if any was printed.
+func (t *thinkingPrinter) done() {
+	if t.printed > 0 {
+		fmt.Println("]")
+	}
 }
 
 func runClassicMode(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *ollama.RewardTracker, showThinking bool, aiDelay int) {
 	reader := bufio.NewReader(os.Stdin)
 
+	// Ctrl+C cancels a request the AI is waiting on rather than leaving the
+	// model to finish it
+	aiCtx := context.Background()
+	if aiPlayer != nil {
+		var stop context.CancelFunc
+		aiCtx, stop = signal.NotifyContext(aiCtx, os.Interrupt)
+		defer stop()
+	}
+
+	// The original game didn't prompt for input
+	prompt := "> "
+	if game.Settings.OldStyle {
+		prompt = ""
+	}
+
 	// Print initial welcome message
 	fmt.Println(game.Output)
 
@@ -187,23 +496,26 @@ func runClassicMode(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *o
 			var response string
 			if cmd, ok := game.NextScriptCommand(); ok {
 				response = cmd
-				fmt.Printf("\n> %s\n", response) // Echo the script command
+				fmt.Printf("\n%s%s\n", prompt, response) // Echo the script command
 			} else if aiPlayer != nil {
 				// AI handles query response
-				ctx := buildGameContext(game, rewardTracker)
-				cmd, thinking, err := aiPlayer.GetCommand(ctx)
+				ctx := bench.GameContext(game, rewardTracker)
+				thinking := &thinkingPrinter{show: showThinking, prefix: "\n"}
+				cmd, _, err := aiPlayer.GetCommandStream(aiCtx, ctx, thinking.print)
+				thinking.done()
+				if aiCtx.Err() != nil {
+					fmt.Println("\nAI interrupted.")
+					return
+				}
 				if err != nil {
 					fmt.Printf("\nAI Error: %v\n", err)
 					return
 				}
-				if thinking != "" && showThinking {
-					fmt.Printf("\n[AI Thinking: %s]\n", thinking)
-				}
 				response = cmd
-				fmt.Printf("\n> %s\n", response)
+				fmt.Printf("\n%s%s\n", prompt, response)
 				time.Sleep(time.Duration(aiDelay) * time.Millisecond)
 			} else {
-				fmt.Print("\n> ")
+				fmt.Print("\n" + prompt)
 				var err error
 				response, err = reader.ReadString('\n')
 				if err != nil {
@@ -239,29 +551,29 @@ func runClassicMode(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *o
 
 		// Get command from script, AI, or user
 		var input string
-		var scoreBefore int
+		locBefore := game.Loc
 		if cmd, ok := game.NextScriptCommand(); ok {
 			input = cmd
-			fmt.Printf("> %s\n", input) // Echo the script command
+			fmt.Printf("%s%s\n", prompt, input) // Echo the script command
 		} else if aiPlayer != nil {
-			// Track score before AI action for reward feedback
-			scoreBefore = game.GetScore()
-
 			// AI generates command based on game context
-			ctx := buildGameContext(game, rewardTracker)
-			cmd, thinking, err := aiPlayer.GetCommand(ctx)
+			ctx := bench.GameContext(game, rewardTracker)
+			thinking := &thinkingPrinter{show: showThinking}
+			cmd, _, err := aiPlayer.GetCommandStream(aiCtx, ctx, thinking.print)
+			thinking.done()
+			if aiCtx.Err() != nil {
+				fmt.Println("AI interrupted.")
+				return
+			}
 			if err != nil {
 				fmt.Printf("AI Error: %v\n", err)
 				return
 			}
-			if thinking != "" && showThinking {
-				fmt.Printf("[AI Thinking: %s]\n", thinking)
-			}
-			fmt.Printf("> %s\n", cmd) // Show AI's command
+			fmt.Printf("%s%s\n", prompt, cmd) // Show AI's command
 			input = cmd
 			time.Sleep(time.Duration(aiDelay) * time.Millisecond)
 		} else {
-			fmt.Print("> ")
+			fmt.Print(prompt)
 			var err error
 			input, err = reader.ReadString('\n')
 			if err != nil {
@@ -271,8 +583,8 @@ func runClassicMode(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *o
 			input = strings.TrimSpace(input)
 		}
 
-		// Handle exit (skip for AI - it doesn't quit)
-		if aiPlayer == nil && (strings.ToLower(input) == "quit" || strings.ToLower(input) == "exit") {
+		// Handle exit (skip for AI - it doesn't quit, and oldstyle leaves QUIT to the game)
+		if aiPlayer == nil && !game.Settings.OldStyle && (strings.ToLower(input) == "quit" || strings.ToLower(input) == "exit") {
 			// Autosave if enabled
 			if err := game.AutoSave(); err != nil && game.Settings.EnableDebug {
 				fmt.Printf("DEBUG: Autosave failed: %s\n", err.Error())
@@ -282,17 +594,15 @@ func runClassicMode(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *o
 		}
 
 		// Process command
-		if err := game.ProcessCommand(input); err != nil {
+		if err := game.ProcessInput(input); err != nil {
 			fmt.Println("Error:", err)
 		} else {
 			fmt.Println(game.Output)
 		}
 
-		// Record reward for AI actions
+		// Reward AI actions once they have played out
 		if aiPlayer != nil && rewardTracker != nil {
-			scoreAfter := game.GetScore()
-			died := game.GameOver // Simplified death detection
-			rewardTracker.RecordAction(input, scoreBefore, scoreAfter, died)
+			rewardTracker.StartAction(int(locBefore), input)
 		}
 	}
 }
@@ -0,0 +1,332 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Cassette holds the replies a model gave, keyed by a hash of the game and
+// the conversation it was sent, so a game played with a live model can be
+// played again without one. It is stored as JSON lines, one interaction per
+// line. A cassette is safe to share between the players of several games:
+// each game's replies are kept apart, although they all start from the same
+// prompt.
+type Cassette struct {
+	mu      sync.Mutex
+	file    *os.File // Set while recording
+	entries map[string][]CassetteEntry
+}
+
+// CassetteEntry is one recorded interaction.
+type CassetteEntry struct {
+	Key           string `json:"key"`                      // Hash of the game and the messages sent
+	Game          int    `json:"game,omitempty"`           // Seed of the game the reply was for
+	Schema        bool   `json:"schema,omitempty"`         // The reply was asked for with a schema
+	SchemaRefused bool   `json:"schema_refused,omitempty"` // The server turned the schema down, so the reply is free text
+	Prompt        string `json:"prompt"`                   // The last message sent, for reading the cassette
+	Response      string `json:"response"`
+}
+
+// CassetteMissError is returned on replay when the cassette has no reply for
+// a conversation, as when the prompts have changed since it was recorded.
+type CassetteMissError struct {
+	Key    string
+	Prompt string // The last message sent
+}
+
+func (e *CassetteMissError) Error() string {
+	return fmt.Sprintf("no recorded reply for conversation %s; the prompt may have changed since the cassette was recorded. Last message:\n%s", e.Key[:12], e.Prompt)
+}
+
+// CreateCassette creates an empty cassette file to record to, replacing any
+// that is there.
+func CreateCassette(filename string) (*Cassette, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+	return &Cassette{file: file, entries: map[string][]CassetteEntry{}}, nil
+}
+
+// LoadCassette reads a recorded cassette to replay.
+func LoadCassette(filename string) (*Cassette, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	c := &Cassette{entries: map[string][]CassetteEntry{}}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry CassetteEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error reading cassette line %d: %w", line, err)
+		}
+		c.entries[entry.Key] = append(c.entries[entry.Key], entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading cassette: %w", err)
+	}
+
+	return c, nil
+}
+
+// Close closes the file being recorded to.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// Len returns the number of interactions on the cassette.
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, entries := range c.entries {
+		n += len(entries)
+	}
+	return n
+}
+
+// structured reports whether the replies were recorded from a backend that
+// follows a schema, so the replay can ask for them in the same way.
+func (c *Cassette) structured() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entries := range c.entries {
+		for _, e := range entries {
+			if e.Schema {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// record adds an interaction, writing it straight to the file so a game that
+// is interrupted keeps what it recorded.
+func (c *Cassette) record(entry CassetteEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[entry.Key] = append(c.entries[entry.Key], entry)
+	if c.file == nil {
+		return nil
+	}
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to record to cassette: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the nth reply recorded for a conversation. When the same
+// conversation was recorded fewer times, the last reply is given again.
+func (c *Cassette) lookup(key string, n int) (CassetteEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries[key]
+	if len(entries) == 0 {
+		return CassetteEntry{}, false
+	}
+	return entries[min(n, len(entries)-1)], true
+}
+
+// cassetteKey hashes a game's conversation.
+func cassetteKey(game int, messages []Message) string {
+	data, _ := json.Marshal(messages)
+	sum := sha256.Sum256(append(strconv.AppendInt(nil, int64(game), 10), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastMessage returns the content of the last message sent.
+func lastMessage(messages []Message) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1].Content
+}
+
+// Recorder is an LLM that passes requests on to another and records each
+// reply on a cassette.
+type Recorder struct {
+	llm      LLM
+	cassette *Cassette
+	game     int // Seed of the game being played
+}
+
+// schemaRecorder is a Recorder for an LLM that can follow a schema.
+type schemaRecorder struct {
+	*Recorder
+}
+
+// NewRecorder wraps llm so its replies to the game with the given seed are
+// recorded on cassette. The recorder can follow a schema if llm can, so the
+// player asks it for the same replies it would ask llm for.
+func NewRecorder(llm LLM, cassette *Cassette, game int) LLM {
+	r := &Recorder{llm: llm, cassette: cassette, game: game}
+	if _, ok := llm.(SchemaLLM); ok {
+		return schemaRecorder{r}
+	}
+	return r
+}
+
+// Chat asks the wrapped LLM and records its reply.
+func (r *Recorder) Chat(ctx context.Context, messages []Message) (string, error) {
+	response, err := r.llm.Chat(ctx, messages)
+	return r.record(messages, CassetteEntry{Response: response}, err)
+}
+
+// ChatStream asks the wrapped LLM, streaming if it can, and records its reply.
+func (r *Recorder) ChatStream(ctx context.Context, messages []Message, onChunk func(chunk string)) (string, error) {
+	streamer, ok := r.llm.(StreamingLLM)
+	if !ok {
+		response, err := r.Chat(ctx, messages)
+		if err == nil && onChunk != nil {
+			onChunk(response)
+		}
+		return response, err
+	}
+
+	response, err := streamer.ChatStream(ctx, messages, onChunk)
+	return r.record(messages, CassetteEntry{Response: response}, err)
+}
+
+// ChatSchema asks the wrapped LLM for a reply matching schema and records it.
+func (r schemaRecorder) ChatSchema(ctx context.Context, messages []Message, schema json.RawMessage, onChunk func(chunk string)) (string, error) {
+	response, err := r.llm.(SchemaLLM).ChatSchema(ctx, messages, schema, onChunk)
+	return r.record(messages, CassetteEntry{Schema: true, SchemaRefused: r.SchemaRefused(), Response: response}, err)
+}
+
+// SchemaRefused reports whether the wrapped LLM's server has turned down a
+// schema.
+func (r schemaRecorder) SchemaRefused() bool {
+	refuser, ok := r.llm.(schemaRefuser)
+	return ok && refuser.SchemaRefused()
+}
+
+// record records a successful reply, given in entry, to messages. Failures
+// aren't recorded, so the replay asks for the reply the game went on to get.
+func (r *Recorder) record(messages []Message, entry CassetteEntry, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+
+	entry.Key, entry.Game, entry.Prompt = cassetteKey(r.game, messages), r.game, lastMessage(messages)
+	if err := r.cassette.record(entry); err != nil {
+		return "", err
+	}
+	return entry.Response, nil
+}
+
+// ReplayLLM is an LLM that gives the replies recorded on a cassette, for
+// playing a game again without a model. It is the LLM for the "replay"
+// backend.
+type ReplayLLM struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	game     int            // Seed of the game being played
+	seen     map[string]int // Times each conversation has been replayed
+	refused  bool           // A reply was recorded after the server turned a schema down
+}
+
+// schemaReplayLLM is a ReplayLLM for replies recorded with a schema.
+type schemaReplayLLM struct {
+	*ReplayLLM
+}
+
+// NewReplayLLM creates an LLM that replays the replies cassette recorded for
+// the game with the given seed. If the replies were recorded from a backend
+// that follows a schema, the replay claims to follow one too, so the player
+// sends the same prompts it did then.
+func NewReplayLLM(cassette *Cassette, game int) LLM {
+	r := &ReplayLLM{cassette: cassette, game: game, seen: map[string]int{}}
+	if cassette.structured() {
+		return schemaReplayLLM{r}
+	}
+	return r
+}
+
+// Chat returns the reply recorded for the conversation, or a
+// *CassetteMissError if there isn't one.
+func (r *ReplayLLM) Chat(ctx context.Context, messages []Message) (string, error) {
+	entry, err := r.replay(ctx, messages)
+	return entry.Response, err
+}
+
+// replay returns the interaction recorded for the conversation.
+func (r *ReplayLLM) replay(ctx context.Context, messages []Message) (CassetteEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return CassetteEntry{}, err
+	}
+
+	key := cassetteKey(r.game, messages)
+	r.mu.Lock()
+	n := r.seen[key]
+	r.seen[key]++
+	r.mu.Unlock()
+
+	entry, ok := r.cassette.lookup(key, n)
+	if !ok {
+		return CassetteEntry{}, &CassetteMissError{Key: key, Prompt: lastMessage(messages)}
+	}
+	return entry, nil
+}
+
+// ChatStream returns the recorded reply, passing it to onChunk whole.
+func (r *ReplayLLM) ChatStream(ctx context.Context, messages []Message, onChunk func(chunk string)) (string, error) {
+	response, err := r.Chat(ctx, messages)
+	if err == nil && onChunk != nil {
+		onChunk(response)
+	}
+	return response, err
+}
+
+// ChatSchema returns the recorded reply; the schema was followed when it
+// was recorded, unless the server turned it down.
+func (r schemaReplayLLM) ChatSchema(ctx context.Context, messages []Message, schema json.RawMessage, onChunk func(chunk string)) (string, error) {
+	entry, err := r.replay(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	if entry.SchemaRefused {
+		r.mu.Lock()
+		r.refused = true
+		r.mu.Unlock()
+	}
+	if onChunk != nil {
+		onChunk(entry.Response)
+	}
+	return entry.Response, nil
+}
+
+// SchemaRefused reports whether the recording's server had turned down a
+// schema by this point, so the player asks for free text as it did then.
+func (r schemaReplayLLM) SchemaRefused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.refused
+}
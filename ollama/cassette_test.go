@@ -0,0 +1,157 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// playContexts has a player play a game context per turn and returns its commands.
+func playContexts(t *testing.T, llm LLM, outputs []string) ([]string, error) {
+	t.Helper()
+	player := NewPlayer(llm, false)
+
+	var commands []string
+	for _, output := range outputs {
+		cmd, _, err := player.GetCommand(context.Background(), &GameContext{GameOutput: output, Vocabulary: testVocabulary})
+		if err != nil {
+			return commands, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.cassette")
+	outputs := []string{"You are standing at the end of a road.", "You are inside a building.", "You are inside a building."}
+
+	cassette, err := CreateCassette(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder, err := NewLLM(BackendConfig{Backend: BackendFake, Script: []string{"EAST", "GET LAMP", "GET KEYS"}, Cassette: cassette})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorded, err := playContexts(t, recorder, outputs)
+	if err != nil {
+		t.Fatalf("unexpected error recording: %v", err)
+	}
+	cassette.Close()
+
+	loaded, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if loaded.Len() != 3 {
+		t.Errorf("expected 3 interactions on the cassette, got %d", loaded.Len())
+	}
+
+	replay, err := NewLLM(BackendConfig{Backend: BackendReplay, Cassette: loaded})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replayed, err := playContexts(t, replay, outputs)
+	if err != nil {
+		t.Fatalf("unexpected error replaying: %v", err)
+	}
+	if len(replayed) != 3 || replayed[0] != recorded[0] || replayed[1] != recorded[1] || replayed[2] != recorded[2] {
+		t.Errorf("expected the replay to play %v, got %v", recorded, replayed)
+	}
+
+	if _, err := NewLLM(BackendConfig{Backend: BackendReplay}); err == nil {
+		t.Error("expected an error replaying without a cassette")
+	}
+}
+
+func TestReplayMissesChangedPrompt(t *testing.T) {
+	cassette, err := CreateCassette(filepath.Join(t.TempDir(), "game.cassette"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cassette.Close()
+
+	if _, err := playContexts(t, NewRecorder(NewScriptedLLM("EAST"), cassette, 1), []string{"You are standing at the end of a road."}); err != nil {
+		t.Fatalf("unexpected error recording: %v", err)
+	}
+
+	_, err = playContexts(t, NewReplayLLM(cassette, 1), []string{"You are standing at the end of a long road."})
+	var miss *CassetteMissError
+	if !errors.As(err, &miss) {
+		t.Fatalf("expected a CassetteMissError, got %v", err)
+	}
+	if miss.Prompt == "" {
+		t.Error("expected the miss to show the prompt that wasn't recorded")
+	}
+}
+
+func TestCassetteKeepsGamesApart(t *testing.T) {
+	cassette, err := CreateCassette(filepath.Join(t.TempDir(), "run.cassette"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cassette.Close()
+
+	// Every game starts from the same prompt, and the model answers each
+	// differently
+	outputs := []string{"You are standing at the end of a road."}
+	for game, reply := range map[int]string{1: "EAST", 2: "WEST", 3: "SOUTH"} {
+		if _, err := playContexts(t, NewRecorder(NewScriptedLLM(reply), cassette, game), outputs); err != nil {
+			t.Fatalf("unexpected error recording game %d: %v", game, err)
+		}
+	}
+
+	for game, want := range map[int]string{3: "SOUTH", 1: "EAST", 2: "WEST"} {
+		commands, err := playContexts(t, NewReplayLLM(cassette, game), outputs)
+		if err != nil || len(commands) != 1 || commands[0] != want {
+			t.Errorf("expected game %d to replay %s, got %v, %v", game, want, commands, err)
+		}
+	}
+
+	var miss *CassetteMissError
+	if _, err := playContexts(t, NewReplayLLM(cassette, 4), outputs); !errors.As(err, &miss) {
+		t.Errorf("expected a game that wasn't recorded to miss, got %v", err)
+	}
+}
+
+func TestCassetteKeepsSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{
+			Message: Message{Role: "assistant", Content: `{"reasoning":"I need light.","verb":"GET","object":"LAMP"}`},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
+
+	cassette, err := CreateCassette(filepath.Join(t.TempDir(), "game.cassette"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cassette.Close()
+
+	recorder := NewRecorder(NewClient(server.URL, "test-model", 0, 0.1), cassette, 1)
+	if _, ok := recorder.(SchemaLLM); !ok {
+		t.Fatal("expected the recorder of a schema backend to follow a schema")
+	}
+	if _, err := playContexts(t, recorder, []string{"It is dark."}); err != nil {
+		t.Fatalf("unexpected error recording: %v", err)
+	}
+
+	replay := NewReplayLLM(cassette, 1)
+	if _, ok := replay.(SchemaLLM); !ok {
+		t.Fatal("expected the replay of schema replies to follow a schema")
+	}
+	commands, err := playContexts(t, replay, []string{"It is dark."})
+	if err != nil || len(commands) != 1 || commands[0] != "GET LAMP" {
+		t.Errorf("expected GET LAMP from the replay, got %v, %v", commands, err)
+	}
+
+	if _, ok := NewRecorder(NewScriptedLLM(), cassette, 1).(SchemaLLM); ok {
+		t.Error("expected the recorder of a plain backend not to follow a schema")
+	}
+}
@@ -1,8 +1,11 @@
 package ollama
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,28 +13,22 @@ import (
 	"time"
 )
 
-// isTimeoutError checks if an error is a timeout error.
-func isTimeoutError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "timeout") ||
-		strings.Contains(errStr, "deadline exceeded")
-}
-
-// Client is an HTTP client for the Ollama API.
+// Client is an HTTP client for the Ollama API. It is the LLM for the
+// "ollama" backend.
 type Client struct {
 	BaseURL     string
 	Model       string
 	Timeout     time.Duration
 	Temperature float64 // 0.0 = deterministic, 1.0 = creative (default 0.7)
+	Retry       RetryPolicy
+	HTTPClient  *http.Client // Shared between requests so connections are reused
 }
 
 // Message represents a chat message.
 type Message struct {
-	Role    string `json:"role"` // "system", "user", "assistant"
-	Content string `json:"content"`
+	Role     string `json:"role"` // "system", "user", "assistant"
+	Content  string `json:"content"`
+	Thinking string `json:"thinking,omitempty"` // Reasoning that Ollama returns apart from the content
 }
 
 // ChatOptions contains model parameters for the Ollama API.
@@ -41,10 +38,11 @@ type ChatOptions struct {
 
 // ChatRequest is the request body for the Ollama chat API.
 type ChatRequest struct {
-	Model    string       `json:"model"`
-	Messages []Message    `json:"messages"`
-	Stream   bool         `json:"stream"`
-	Options  *ChatOptions `json:"options,omitempty"`
+	Model    string          `json:"model"`
+	Messages []Message       `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"` // JSON schema the reply must match
+	Options  *ChatOptions    `json:"options,omitempty"`
 }
 
 // ChatResponse is the response from the Ollama chat API.
@@ -53,6 +51,7 @@ type ChatResponse struct {
 	CreatedAt string  `json:"created_at"`
 	Message   Message `json:"message"`
 	Done      bool    `json:"done"`
+	Error     string  `json:"error,omitempty"` // Set when a stream fails part way
 }
 
 // NewClient creates a new Ollama client with default settings.
@@ -74,55 +73,165 @@ func NewClient(baseURL, model string, timeout time.Duration, temperature float64
 		Model:       model,
 		Timeout:     timeout,
 		Temperature: temperature,
+		Retry:       DefaultRetryPolicy,
+		HTTPClient:  newHTTPClient(),
 	}
 }
 
 // Chat sends a chat request to Ollama and returns the response content.
-func (c *Client) Chat(messages []Message) (string, error) {
-	reqBody := ChatRequest{
-		Model:    c.Model,
-		Messages: messages,
-		Stream:   false,
-		Options: &ChatOptions{
-			Temperature: c.Temperature,
-		},
+func (c *Client) Chat(ctx context.Context, messages []Message) (string, error) {
+	return c.chat(ctx, messages, nil)
+}
+
+// ChatStream sends a chat request to Ollama and passes the response to
+// onChunk piece by piece as the model generates it. Ollama sends one JSON
+// object per line. Reasoning is passed on in <think> tags, as Chat returns it.
+// The timeout limits how long the model may go without sending anything.
+func (c *Client) ChatStream(ctx context.Context, messages []Message, onChunk func(chunk string)) (string, error) {
+	return c.chatStream(ctx, messages, nil, onChunk)
+}
+
+// ChatSchema sends a chat request with the schema as Ollama's format, so the
+// model replies with JSON matching it. The reply streams to onChunk if it
+// isn't nil.
+func (c *Client) ChatSchema(ctx context.Context, messages []Message, schema json.RawMessage, onChunk func(chunk string)) (string, error) {
+	if onChunk != nil {
+		return c.chatStream(ctx, messages, schema, onChunk)
 	}
+	return c.chat(ctx, messages, schema)
+}
 
-	jsonData, err := json.Marshal(reqBody)
+func (c *Client) chat(ctx context.Context, messages []Message, format json.RawMessage) (string, error) {
+	newRequest, err := c.chatRequest(ctx, messages, false, format)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
-	url := c.BaseURL + "/api/chat"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	body, err := postWithRetry(ctx, c.httpClient(), c.Retry, c.Timeout, c.Model, newRequest)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", c.explain(err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: c.Timeout}
-	resp, err := client.Do(req)
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if chatResp.Message.Thinking != "" {
+		return "<think>" + chatResp.Message.Thinking + "</think>\n" + chatResp.Message.Content, nil
+	}
+	return chatResp.Message.Content, nil
+}
+
+func (c *Client) chatStream(ctx context.Context, messages []Message, format json.RawMessage, onChunk func(chunk string)) (string, error) {
+	newRequest, err := c.chatRequest(ctx, messages, true, format)
 	if err != nil {
-		// Check if it's a timeout error
-		if isTimeoutError(err) {
-			return "", fmt.Errorf("Ollama request timed out after %v. The model may be loading or is slow to respond.\nTry: -ai-timeout 180 for a longer timeout, or use a smaller/faster model", c.Timeout)
-		}
-		return "", fmt.Errorf("failed to send request to Ollama at %s: %w\nMake sure Ollama is running (ollama serve)", c.BaseURL, err)
+		return "", err
 	}
+
+	resp, a, err := openWithRetry(ctx, c.httpClient(), c.Retry, c.Timeout, c.Model, newRequest)
+	if err != nil {
+		return "", c.explain(err)
+	}
+	defer a.stop()
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		if resp.StatusCode == http.StatusNotFound {
-			return "", fmt.Errorf("model '%s' not found. Run 'ollama pull %s' to download it", c.Model, c.Model)
+	var response strings.Builder
+	thinking := false
+	emit := func(chunk string) {
+		if chunk == "" {
+			return
+		}
+		response.WriteString(chunk)
+		if onChunk != nil {
+			onChunk(chunk)
 		}
-		return "", fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	var chatResp ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		a.touch()
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("failed to decode response: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", &ServerError{StatusCode: resp.StatusCode, Body: chunk.Error}
+		}
+
+		if chunk.Message.Thinking != "" && !thinking {
+			emit("<think>")
+			thinking = true
+		}
+		emit(chunk.Message.Thinking)
+		if thinking && (chunk.Message.Content != "" || chunk.Done) {
+			emit("</think>\n")
+			thinking = false
+		}
+		emit(chunk.Message.Content)
+
+		if chunk.Done {
+			return response.String(), nil
+		}
 	}
+	if err := scanner.Err(); err != nil {
+		return "", a.failed(ctx, err)
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return "", &ConnectionError{URL: a.url, Err: io.ErrUnexpectedEOF}
+}
 
-	return chatResp.Message.Content, nil
+// chatRequest returns a function that builds a request to /api/chat, fresh
+// for each attempt. The temperature is raised if ctx asks for it.
+func (c *Client) chatRequest(ctx context.Context, messages []Message, stream bool, format json.RawMessage) (func(ctx context.Context) (*http.Request, error), error) {
+	reqBody := ChatRequest{
+		Model:    c.Model,
+		Messages: messages,
+		Stream:   stream,
+		Format:   format,
+		Options: &ChatOptions{
+			Temperature: temperature(ctx, c.Temperature),
+		},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := c.BaseURL + "/api/chat"
+	return func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, nil
+}
+
+// explain adds advice on fixing an error where there is some.
+func (c *Client) explain(err error) error {
+	var notFound *ModelNotFoundError
+	if errors.As(err, &notFound) {
+		notFound.Hint = fmt.Sprintf("Run 'ollama pull %s' to download it", c.Model)
+	}
+	return err
+}
+
+// httpClient returns the shared HTTP client, for clients built without
+// NewClient.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		c.HTTPClient = newHTTPClient()
+	}
+	return c.HTTPClient
 }
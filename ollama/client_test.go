@@ -1,7 +1,9 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -61,7 +63,7 @@ func TestChat(t *testing.T) {
 		{Role: "user", Content: "You are at the entrance to a cave."},
 	}
 
-	response, err := client.Chat(messages)
+	response, err := client.Chat(context.Background(), messages)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -78,7 +80,7 @@ func TestChatModelNotFound(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "nonexistent-model", 0, 0.1)
-	_, err := client.Chat([]Message{{Role: "user", Content: "test"}})
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
 
 	if err == nil {
 		t.Fatal("expected error for missing model")
@@ -86,6 +88,10 @@ func TestChatModelNotFound(t *testing.T) {
 	if !contains(err.Error(), "not found") {
 		t.Errorf("expected 'not found' in error, got: %s", err.Error())
 	}
+	var notFound *ModelNotFoundError
+	if !errors.As(err, &notFound) || notFound.Model != "nonexistent-model" {
+		t.Errorf("expected a ModelNotFoundError, got %v", err)
+	}
 }
 
 func TestChatServerError(t *testing.T) {
@@ -96,11 +102,16 @@ func TestChatServerError(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-model", 0, 0.1)
-	_, err := client.Chat([]Message{{Role: "user", Content: "test"}})
+	client.Retry = RetryPolicy{}
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
 
 	if err == nil {
 		t.Fatal("expected error for server error")
 	}
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected a ServerError with status 500, got %v", err)
+	}
 }
 
 func contains(s, substr string) bool {
@@ -0,0 +1,96 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ScriptedLLM is a fake LLM that replies with a fixed script, for tests and
+// for trying the AI player without a model. It is the LLM for the "fake"
+// backend.
+type ScriptedLLM struct {
+	mu        sync.Mutex
+	responses []string
+	next      int
+	calls     [][]Message
+}
+
+// NewScriptedLLM creates a fake that gives the responses in order, starting
+// again from the first when they run out. With no responses it always
+// replies LOOK.
+func NewScriptedLLM(responses ...string) *ScriptedLLM {
+	if len(responses) == 0 {
+		responses = []string{"LOOK"}
+	}
+	return &ScriptedLLM{responses: responses}
+}
+
+// Chat records the messages and returns the next scripted response.
+func (s *ScriptedLLM) Chat(ctx context.Context, messages []Message) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, append([]Message(nil), messages...))
+	response := s.responses[s.next%len(s.responses)]
+	s.next++
+	return response, nil
+}
+
+// ChatStream returns the next scripted response, passing it to onChunk a
+// word at a time.
+func (s *ScriptedLLM) ChatStream(ctx context.Context, messages []Message, onChunk func(chunk string)) (string, error) {
+	response, err := s.Chat(ctx, messages)
+	if err != nil || onChunk == nil {
+		return response, err
+	}
+
+	for rest := response; rest != ""; {
+		end := strings.IndexAny(rest[1:], " \n") + 1
+		if end == 0 {
+			end = len(rest)
+		}
+		onChunk(rest[:end])
+		rest = rest[end:]
+	}
+	return response, nil
+}
+
+// Calls returns the messages sent in each call so far.
+func (s *ScriptedLLM) Calls() [][]Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]Message(nil), s.calls...)
+}
+
+// LoadScript reads fake responses from a file, one per line, skipping blank
+// lines and # comments as game scripts do.
+func LoadScript(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open AI script: %w", err)
+	}
+	defer file.Close()
+
+	var responses []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		responses = append(responses, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading AI script: %w", err)
+	}
+
+	return responses, nil
+}
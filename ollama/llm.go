@@ -0,0 +1,120 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Backends that NewLLM can create.
+const (
+	BackendOllama = "ollama" // Ollama's /api/chat
+	BackendOpenAI = "openai" // Any OpenAI-compatible /v1/chat/completions server
+	BackendFake   = "fake"   // Scripted responses, no server needed
+	BackendReplay = "replay" // Responses recorded on a cassette, no server needed
+)
+
+// LLM is a chat model the AI player can ask for its next command.
+type LLM interface {
+	// Chat sends the conversation so far and returns the model's reply.
+	Chat(ctx context.Context, messages []Message) (string, error)
+}
+
+// StreamingLLM is an LLM that can pass on its reply as the model generates
+// it, so a player can watch it think.
+type StreamingLLM interface {
+	LLM
+	// ChatStream works as Chat, also calling onChunk with each piece of the
+	// reply as it arrives.
+	ChatStream(ctx context.Context, messages []Message, onChunk func(chunk string)) (string, error)
+}
+
+// SchemaLLM is an LLM that can be made to reply with JSON matching a schema,
+// so the player needn't guess the command from free text.
+type SchemaLLM interface {
+	LLM
+	// ChatSchema works as Chat, constraining the reply to JSON matching
+	// schema. If onChunk isn't nil it is called with the reply as it arrives.
+	ChatSchema(ctx context.Context, messages []Message, schema json.RawMessage, onChunk func(chunk string)) (string, error)
+}
+
+// schemaRefuser is a SchemaLLM whose server may turn schemas down after all.
+type schemaRefuser interface {
+	SchemaRefused() bool
+}
+
+// followsSchema returns client as a SchemaLLM if it can follow a schema, as
+// it can't once its server has refused one.
+func followsSchema(client LLM) (SchemaLLM, bool) {
+	schemaLLM, ok := client.(SchemaLLM)
+	if refuser, can := client.(schemaRefuser); ok && can && refuser.SchemaRefused() {
+		return nil, false
+	}
+	return schemaLLM, ok
+}
+
+type minTemperatureKey struct{}
+
+// WithMinTemperature asks for replies at temperature t or higher, for
+// shaking a model out of a rut. Backends without a temperature ignore it.
+func WithMinTemperature(ctx context.Context, t float64) context.Context {
+	return context.WithValue(ctx, minTemperatureKey{}, t)
+}
+
+// temperature returns the temperature a client should ask for: its own, or
+// more if ctx asks for it.
+func temperature(ctx context.Context, own float64) float64 {
+	if t, ok := ctx.Value(minTemperatureKey{}).(float64); ok && t > own {
+		return t
+	}
+	return own
+}
+
+// BackendConfig selects and configures an LLM backend.
+type BackendConfig struct {
+	Backend     string        // BackendOllama, BackendOpenAI or BackendFake
+	URL         string        // Server URL; empty for the backend's default
+	Model       string        // Model name as the server knows it
+	APIKey      string        // Bearer token for OpenAI-compatible servers that need one
+	Timeout     time.Duration // Per request timeout
+	Retries     int           // Retries after a server error or failed connection
+	Temperature float64       // 0.0 = deterministic, 1.0 = creative
+	Script      []string      // Responses for the fake backend
+	Cassette    *Cassette     // Replayed by the replay backend; the others record to it if set
+	Game        int           // Seed of the game played, keeping its replies apart on a shared cassette
+}
+
+// NewLLM creates the LLM for a backend, recording its replies if the config
+// has a cassette.
+func NewLLM(cfg BackendConfig) (LLM, error) {
+	if cfg.Backend == BackendReplay {
+		if cfg.Cassette == nil {
+			return nil, fmt.Errorf("the %s backend needs a cassette to replay", BackendReplay)
+		}
+		return NewReplayLLM(cfg.Cassette, cfg.Game), nil
+	}
+
+	llm, err := newBackend(cfg)
+	if err != nil || cfg.Cassette == nil {
+		return llm, err
+	}
+	return NewRecorder(llm, cfg.Cassette, cfg.Game), nil
+}
+
+// newBackend creates the LLM that talks to a backend.
+func newBackend(cfg BackendConfig) (LLM, error) {
+	switch cfg.Backend {
+	case "", BackendOllama:
+		client := NewClient(cfg.URL, cfg.Model, cfg.Timeout, cfg.Temperature)
+		client.Retry.MaxRetries = cfg.Retries
+		return client, nil
+	case BackendOpenAI:
+		client := NewOpenAIClient(cfg.URL, cfg.Model, cfg.APIKey, cfg.Timeout, cfg.Temperature)
+		client.Retry.MaxRetries = cfg.Retries
+		return client, nil
+	case BackendFake:
+		return NewScriptedLLM(cfg.Script...), nil
+	}
+	return nil, fmt.Errorf("unknown AI backend %q (want %s, %s, %s or %s)", cfg.Backend, BackendOllama, BackendOpenAI, BackendFake, BackendReplay)
+}
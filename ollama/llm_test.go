@@ -0,0 +1,161 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLLM(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    string
+	}{
+		{"", "*ollama.Client"},
+		{BackendOllama, "*ollama.Client"},
+		{BackendOpenAI, "*ollama.OpenAIClient"},
+		{BackendFake, "*ollama.ScriptedLLM"},
+	}
+
+	for _, tt := range tests {
+		llm, err := NewLLM(BackendConfig{Backend: tt.backend, Model: "test-model"})
+		if err != nil {
+			t.Fatalf("NewLLM(%q) returned error: %v", tt.backend, err)
+		}
+		if got := fmt.Sprintf("%T", llm); got != tt.want {
+			t.Errorf("NewLLM(%q) = %s, want %s", tt.backend, got, tt.want)
+		}
+	}
+
+	if _, err := NewLLM(BackendConfig{Backend: "carrier-pigeon"}); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestScriptedLLM(t *testing.T) {
+	llm := NewScriptedLLM("GET LAMP", "NORTH")
+	messages := []Message{{Role: "user", Content: "test"}}
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		response, err := llm.Chat(context.Background(), messages)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, response)
+	}
+
+	if got[0] != "GET LAMP" || got[1] != "NORTH" || got[2] != "GET LAMP" {
+		t.Errorf("expected the script in order, then repeated, got %v", got)
+	}
+	if len(llm.Calls()) != 3 {
+		t.Errorf("expected 3 calls recorded, got %d", len(llm.Calls()))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := llm.Chat(ctx, messages); err == nil {
+		t.Error("expected an error for a cancelled context")
+	}
+}
+
+func TestLoadScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.txt")
+	os.WriteFile(path, []byte("# opening moves\nIN\n\nGET LAMP\n"), 0o644)
+
+	responses, err := LoadScript(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(responses) != 2 || responses[0] != "IN" || responses[1] != "GET LAMP" {
+		t.Errorf("expected IN and GET LAMP, got %v", responses)
+	}
+}
+
+func TestPlayerWithScriptedLLM(t *testing.T) {
+	llm := NewScriptedLLM("<think>The lamp will help.</think>\nget lamp")
+	player := NewPlayer(llm, true)
+
+	cmd, thinking, err := player.GetCommand(context.Background(), &GameContext{GameOutput: "There is a lamp here."})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd != "GET LAMP" || thinking != "The lamp will help." {
+		t.Errorf("expected GET LAMP with thinking, got %q, %q", cmd, thinking)
+	}
+
+	calls := llm.Calls()
+	if len(calls) != 1 || calls[0][0].Role != "system" || calls[0][1].Role != "user" {
+		t.Errorf("expected the system prompt then the game context, got %+v", calls)
+	}
+}
+
+func TestPlayerRetriesRejectedCommands(t *testing.T) {
+	llm := NewScriptedLLM("GET SHINY", "GET WIDGET", "GET LAMP")
+	player := NewPlayer(llm, false)
+	player.SetValidator(func(command string) error {
+		if command != "GET LAMP" {
+			return fmt.Errorf("%s is not a word I know; objects here are LAMP, KEYS.", command[4:])
+		}
+		return nil
+	}, 3)
+
+	cmd, _, err := player.GetCommand(context.Background(), &GameContext{GameOutput: "There is a lamp here."})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd != "GET LAMP" || player.Rejected != 2 {
+		t.Errorf("expected GET LAMP after 2 rejections, got %q after %d", cmd, player.Rejected)
+	}
+
+	calls := llm.Calls()
+	last := calls[2][len(calls[2])-1]
+	if last.Role != "user" || !strings.Contains(last.Content, "WIDGET is not a word I know; objects here are LAMP, KEYS.") {
+		t.Errorf("expected the reason to be fed back, got %+v", last)
+	}
+
+	// The last try is played even if it is rejected
+	llm = NewScriptedLLM("GET SHINY")
+	player = NewPlayer(llm, false)
+	player.SetValidator(func(string) error { return fmt.Errorf("no") }, 2)
+	cmd, _, err = player.GetCommand(context.Background(), &GameContext{})
+	if err != nil || cmd != "GET SHINY" || len(llm.Calls()) != 2 {
+		t.Errorf("expected GET SHINY after 2 tries, got %q, %v after %d", cmd, err, len(llm.Calls()))
+	}
+}
+
+func TestPlayerNotebookInSystemPrompt(t *testing.T) {
+	llm := NewScriptedLLM("LOOK")
+	player := NewPlayer(llm, false)
+
+	// The notebook is kept for memoryInterval turns, then brought up to
+	// date, and brought up to date straight away if the game goes back
+	steps := []struct {
+		turns  int
+		memory string
+		want   string
+	}{
+		{1, "PLACES VISITED (1): Front of building", "(1): Front of building"},
+		{5, "PLACES VISITED (2): Front of building; Inside building", "(1): Front of building"},
+		{11, "PLACES VISITED (3): Front of building; Inside building; Valley", "(3)"},
+		{4, "PLACES VISITED (2): Front of building; Inside building", "(2)"},
+	}
+	for i, step := range steps {
+		if _, _, err := player.GetCommand(context.Background(), &GameContext{Turns: step.turns, Memory: step.memory}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		system := llm.Calls()[i][0].Content
+		if !strings.Contains(system, "YOUR NOTEBOOK") || !strings.Contains(system, step.want) {
+			t.Errorf("turn %d: expected the notebook with %q in the system prompt, got:\n%s", step.turns, step.want, system)
+		}
+	}
+
+	player.Reset()
+	player.GetCommand(context.Background(), &GameContext{})
+	if calls := llm.Calls(); strings.Contains(calls[len(calls)-1][0].Content, "YOUR NOTEBOOK") {
+		t.Error("expected no notebook after a reset")
+	}
+}
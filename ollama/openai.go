@@ -0,0 +1,187 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// OpenAIClient is an HTTP client for servers that speak the OpenAI chat
+// completions API, such as the llama.cpp server, vLLM and LM Studio. It is
+// the LLM for the "openai" backend.
+type OpenAIClient struct {
+	BaseURL     string
+	Model       string
+	APIKey      string
+	Timeout     time.Duration
+	Temperature float64
+	Retry       RetryPolicy
+	HTTPClient  *http.Client
+
+	schemaRefused atomic.Bool // The server has turned down a response_format
+}
+
+// OpenAIChatRequest is the request body for /v1/chat/completions.
+type OpenAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []Message             `json:"messages"`
+	Temperature    float64               `json:"temperature"`
+	Stream         bool                  `json:"stream"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// OpenAIResponseFormat asks for a reply that is JSON matching a schema.
+type OpenAIResponseFormat struct {
+	Type       string `json:"type"` // "json_schema"
+	JSONSchema struct {
+		Name   string          `json:"name"`
+		Schema json.RawMessage `json:"schema"`
+		Strict bool            `json:"strict"`
+	} `json:"json_schema"`
+}
+
+// OpenAIChatResponse is the response from /v1/chat/completions.
+type OpenAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Role             string `json:"role"`
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content,omitempty"` // Sent by reasoning models on llama.cpp and vLLM
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// NewOpenAIClient creates a client for an OpenAI-compatible server. The base
+// URL may include the /v1 prefix or not.
+func NewOpenAIClient(baseURL, model, apiKey string, timeout time.Duration, temperature float64) *OpenAIClient {
+	if baseURL == "" {
+		baseURL = "http://localhost:8080" // llama.cpp server
+	}
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+	if timeout <= 0 {
+		timeout = 240 * time.Second
+	}
+	if temperature < 0 {
+		temperature = 0.1
+	}
+	return &OpenAIClient{
+		BaseURL:     baseURL,
+		Model:       model,
+		APIKey:      apiKey,
+		Timeout:     timeout,
+		Temperature: temperature,
+		Retry:       DefaultRetryPolicy,
+		HTTPClient:  newHTTPClient(),
+	}
+}
+
+// Chat sends a chat completion request and returns the response content.
+// Reasoning the server returns separately is put back in <think> tags, as
+// Ollama's models give it, so the player can show it.
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	return c.chat(ctx, messages, nil)
+}
+
+// ChatSchema sends a chat completion request with the schema as the
+// response_format, so the model replies with JSON matching it. Not every
+// server supports that; once one turns the format down the request is sent
+// again without it, and every request after is sent as Chat would. The
+// reply doesn't stream; it is passed to onChunk whole once it arrives.
+func (c *OpenAIClient) ChatSchema(ctx context.Context, messages []Message, schema json.RawMessage, onChunk func(chunk string)) (string, error) {
+	var format *OpenAIResponseFormat
+	if !c.SchemaRefused() {
+		format = &OpenAIResponseFormat{Type: "json_schema"}
+		format.JSONSchema.Name = "command"
+		format.JSONSchema.Schema = schema
+		format.JSONSchema.Strict = true
+	}
+
+	response, err := c.chat(ctx, messages, format)
+	if format != nil && refusesFormat(err) {
+		c.schemaRefused.Store(true)
+		response, err = c.chat(ctx, messages, nil)
+	}
+	if err == nil && onChunk != nil {
+		onChunk(response)
+	}
+	return response, err
+}
+
+// SchemaRefused reports whether the server has turned down a schema, so the
+// player should ask for free text instead.
+func (c *OpenAIClient) SchemaRefused() bool {
+	return c.schemaRefused.Load()
+}
+
+// refusesFormat reports whether a request was turned down because of its
+// response_format, as servers that don't support schemas do.
+func refusesFormat(err error) bool {
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	return (serverErr.StatusCode == http.StatusBadRequest || serverErr.StatusCode == http.StatusUnprocessableEntity) &&
+		strings.Contains(serverErr.Body, "response_format")
+}
+
+func (c *OpenAIClient) chat(ctx context.Context, messages []Message, format *OpenAIResponseFormat) (string, error) {
+	reqBody := OpenAIChatRequest{
+		Model:          c.Model,
+		Messages:       messages,
+		Temperature:    temperature(ctx, c.Temperature),
+		Stream:         false,
+		ResponseFormat: format,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := c.BaseURL + "/v1/chat/completions"
+	body, err := postWithRetry(ctx, c.httpClient(), c.Retry, c.Timeout, c.Model, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.APIKey)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var chatResp OpenAIChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("chat completions response has no choices")
+	}
+
+	msg := chatResp.Choices[0].Message
+	if msg.ReasoningContent != "" {
+		return "<think>" + msg.ReasoningContent + "</think>\n" + msg.Content, nil
+	}
+	return msg.Content, nil
+}
+
+// httpClient returns the shared HTTP client, for clients built without
+// NewOpenAIClient.
+func (c *OpenAIClient) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		c.HTTPClient = newHTTPClient()
+	}
+	return c.HTTPClient
+}
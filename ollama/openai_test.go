@@ -0,0 +1,121 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// openAIServer returns a stand-in chat completions server replying with
+// content, and the last request it received.
+func openAIServer(t *testing.T, content, reasoning string) (*httptest.Server, *OpenAIChatRequest, *http.Header) {
+	t.Helper()
+	var got OpenAIChatRequest
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("expected path /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if r.Method != "POST" {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		header = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":` +
+			jsonString(content) + `,"reasoning_content":` + jsonString(reasoning) + `},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &got, &header
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func TestOpenAIChat(t *testing.T) {
+	server, got, header := openAIServer(t, "NORTH", "")
+
+	client := NewOpenAIClient(server.URL, "test-model", "secret", 0, 0.3)
+	response, err := client.Chat(context.Background(), []Message{
+		{Role: "system", Content: "Play the game."},
+		{Role: "user", Content: "You are at the end of a road."},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "NORTH" {
+		t.Errorf("expected NORTH, got %s", response)
+	}
+
+	if got.Model != "test-model" || got.Temperature != 0.3 || got.Stream {
+		t.Errorf("unexpected request: %+v", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" {
+		t.Errorf("expected the system and user messages, got %+v", got.Messages)
+	}
+	if auth := header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("expected bearer token, got %q", auth)
+	}
+}
+
+func TestOpenAIChatReasoning(t *testing.T) {
+	server, _, _ := openAIServer(t, "GET LAMP", "It is dark ahead.")
+
+	client := NewOpenAIClient(server.URL+"/v1", "test-model", "", 0, 0.1)
+	response, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	command, thinking := parseResponse(response)
+	if command != "GET LAMP" || thinking != "It is dark ahead." {
+		t.Errorf("expected GET LAMP with thinking, got %q, %q", command, thinking)
+	}
+}
+
+func TestOpenAIChatErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"model not found", http.StatusNotFound, `{"error":{"message":"model not found"}}`},
+		{"server error", http.StatusInternalServerError, "internal server error"},
+		{"no choices", http.StatusOK, `{"choices":[]}`},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		client := NewOpenAIClient(server.URL, "test-model", "", 0, 0.1)
+		client.Retry = RetryPolicy{}
+		if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}}); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		server.Close()
+	}
+}
+
+func TestNewOpenAIClient(t *testing.T) {
+	c := NewOpenAIClient("", "local", "", 0, -1)
+	if c.BaseURL != "http://localhost:8080" {
+		t.Errorf("expected default BaseURL, got %s", c.BaseURL)
+	}
+
+	c = NewOpenAIClient("http://example.com:1234/v1/", "local", "", 0, 0.5)
+	if c.BaseURL != "http://example.com:1234" {
+		t.Errorf("expected /v1 to be trimmed, got %s", c.BaseURL)
+	}
+}
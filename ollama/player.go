@@ -1,6 +1,7 @@
 package ollama
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -65,32 +66,52 @@ Follow these steps at the start of the game:
 
 // GameContext provides rich context about the current game state.
 type GameContext struct {
-	GameOutput      string   // The latest output from the game
-	LocationDesc    string   // Description of current location
-	VisibleObjects  []string // Objects at current location
-	Inventory       []string // Items being carried
-	Score           int      // Current score
-	Turns           int      // Number of turns taken
-	Hints           []string // State-aware hints/suggested actions
-	RewardFeedback  []string // Recent actions with their score changes
-	ValidActions    []string // Valid action verbs (GET, DROP, LOOK, etc.)
-	ValidDirections []string // Valid movement directions (NORTH, SOUTH, etc.)
-	ValidObjects    []string // Objects player can interact with
+	GameOutput      string      // The latest output from the game
+	LocationDesc    string      // Description of current location
+	VisibleObjects  []string    // Objects at current location
+	Inventory       []string    // Items being carried
+	Score           int         // Current score
+	Turns           int         // Number of turns taken
+	Hints           []string    // State-aware hints/suggested actions
+	RewardFeedback  []string    // Recent actions with their rewards
+	RewardGuide     string      // What the player is rewarded for
+	ValidActions    []string    // Valid action verbs (GET, DROP, LOOK, etc.)
+	ValidDirections []string    // Valid movement directions (NORTH, SOUTH, etc.)
+	ValidObjects    []string    // Objects player can interact with
+	Vocabulary      []string    // Every word the game knows, for checking replies
+	Memory          string      // The game's notebook of what the player has learned
+	Stuck           *StuckEvent // Set while the player is going round in circles
 }
 
 // ActionReward represents the outcome of a single action.
 type ActionReward struct {
 	Action      string
+	Location    int // Where the action was taken; 0 if not known
 	ScoreBefore int
 	ScoreAfter  int
-	Outcome     string // "positive", "negative", "neutral", "death"
+	Outcome     string       // "positive", "negative", "neutral", "death"
+	Progress    bool         // The action was taken somewhere new, or gained something
+	Events      RewardEvents // What the action achieved
+	Solved      []string     // Puzzles the action solved
+	Reward      float64      // What the action was worth, by the tracker's weights
 }
 
 // RewardTracker tracks action outcomes for reinforcement feedback.
 type RewardTracker struct {
-	History    []ActionReward
-	MaxHistory int
-	LastScore  int
+	History     []ActionReward
+	MaxHistory  int
+	LastScore   int
+	Weights     RewardWeights // What each thing the player achieves is worth
+	Total       float64       // Reward for all the actions recorded
+	Totals      RewardEvents  // What all the actions recorded achieved
+	Actions     int           // Actions recorded in all
+	StuckEvents []StuckEvent  // Each time the player got stuck, at its worst
+
+	seen          map[int]bool // Locations actions have been taken at
+	sinceProgress int          // Actions since one was taken somewhere new or gained something
+	stuckFor      int          // Actions since the player got stuck; -1 if it isn't
+	progress      *Progress    // The game's tally when last observed
+	pending       *ActionReward
 }
 
 // NewRewardTracker creates a new reward tracker.
@@ -99,25 +120,89 @@ func NewRewardTracker() *RewardTracker {
 		History:    make([]ActionReward, 0),
 		MaxHistory: 10,
 		LastScore:  0,
+		Weights:    DefaultRewardWeights(),
+		seen:       make(map[int]bool),
+		stuckFor:   -1,
 	}
 }
 
 // RecordAction records an action and its outcome.
 func (rt *RewardTracker) RecordAction(action string, scoreBefore, scoreAfter int, died bool) {
-	outcome := "neutral"
+	rt.RecordActionAt(0, action, scoreBefore, scoreAfter, died)
+}
+
+// RecordActionAt records an action taken at a location and its outcome by
+// the score alone. Knowing the location lets CheckStuck tell when the player
+// is going round in circles.
+func (rt *RewardTracker) RecordActionAt(location int, action string, scoreBefore, scoreAfter int, died bool) {
+	after := Progress{Score: scoreAfter}
 	if died {
+		after.Deaths = 1
+	}
+	rt.record(location, action, Progress{Score: scoreBefore}, after)
+}
+
+// StartAction notes an action about to be played at a location. What it
+// achieved isn't known until the game has played it out, moving the player
+// and showing them where they have got to, so it is recorded by the next
+// Observe.
+func (rt *RewardTracker) StartAction(location int, action string) {
+	rt.pending = &ActionReward{Action: action, Location: location}
+}
+
+// Observe notes the game's progress when it next waits for the player,
+// recording the action started since it was last observed, if there was
+// one, with what it achieved. It reports whether an action was recorded.
+func (rt *RewardTracker) Observe(now Progress) bool {
+	before, pending := rt.progress, rt.pending
+	rt.progress, rt.pending = &now, nil
+	if before == nil || pending == nil {
+		return false
+	}
+	rt.record(pending.Location, pending.Action, *before, now)
+	return true
+}
+
+// record records an action taken at a location, rewarding it for what the
+// game's progress shows it achieved.
+func (rt *RewardTracker) record(location int, action string, before, after Progress) {
+	events, solved := since(before, after)
+	gain := rt.Weights.gain(events)
+	reward := rt.Weights.Reward(events)
+
+	outcome := "neutral"
+	if events.Deaths > 0 {
 		outcome = "death"
-	} else if scoreAfter > scoreBefore {
+	} else if gain > 0 {
 		outcome = "positive"
-	} else if scoreAfter < scoreBefore {
+	} else if gain < 0 {
 		outcome = "negative"
 	}
 
+	if rt.seen == nil {
+		rt.seen = make(map[int]bool)
+	}
+	progress := gain > 0 || (location > 0 && !rt.seen[location])
+	rt.seen[location] = true
+	rt.Actions++
+	if progress {
+		rt.sinceProgress = 0
+	} else {
+		rt.sinceProgress++
+	}
+	rt.Total += reward
+	rt.Totals.add(events)
+
 	rt.History = append(rt.History, ActionReward{
 		Action:      action,
-		ScoreBefore: scoreBefore,
-		ScoreAfter:  scoreAfter,
+		Location:    location,
+		ScoreBefore: before.Score,
+		ScoreAfter:  after.Score,
 		Outcome:     outcome,
+		Progress:    progress,
+		Events:      events,
+		Solved:      solved,
+		Reward:      reward,
 	})
 
 	// Trim history if needed
@@ -125,24 +210,27 @@ func (rt *RewardTracker) RecordAction(action string, scoreBefore, scoreAfter int
 		rt.History = rt.History[1:]
 	}
 
-	rt.LastScore = scoreAfter
+	rt.LastScore = after.Score
 }
 
 // GetFeedback returns formatted feedback strings for recent actions.
 func (rt *RewardTracker) GetFeedback() []string {
 	var feedback []string
 	for _, ar := range rt.History {
-		change := ar.ScoreAfter - ar.ScoreBefore
+		reasons := strings.Join(rt.Weights.reasons(ar.Events, ar.Solved), ", ")
 		var msg string
 		switch ar.Outcome {
 		case "positive":
-			msg = fmt.Sprintf("%s: +%d points! Good move!", ar.Action, change)
+			msg = fmt.Sprintf("%s: %+g (%s). Good move!", ar.Action, ar.Reward, reasons)
 		case "negative":
-			msg = fmt.Sprintf("%s: %d points. Avoid this action.", ar.Action, change)
+			msg = fmt.Sprintf("%s: %+g (%s). Avoid this action.", ar.Action, ar.Reward, reasons)
 		case "death":
-			msg = fmt.Sprintf("%s: DIED! Never do this again!", ar.Action)
+			msg = fmt.Sprintf("%s: DIED (%+g)! Never do this again!", ar.Action, ar.Reward)
 		default:
-			msg = fmt.Sprintf("%s: no score change", ar.Action)
+			msg = fmt.Sprintf("%s: nothing gained", ar.Action)
+			if ar.Reward != 0 {
+				msg = fmt.Sprintf("%s: %+g, nothing gained", ar.Action, ar.Reward)
+			}
 		}
 		feedback = append(feedback, msg)
 	}
@@ -220,6 +308,9 @@ func (gc *GameContext) FormatContext() string {
 	// Include reward feedback from recent actions
 	if len(gc.RewardFeedback) > 0 {
 		sb.WriteString("\n=== RECENT ACTION REWARDS ===\n")
+		if gc.RewardGuide != "" {
+			fmt.Fprintf(&sb, "Rewards: %s\n", gc.RewardGuide)
+		}
 		// Show last few rewards (most recent last)
 		start := 0
 		if len(gc.RewardFeedback) > 5 {
@@ -230,6 +321,13 @@ func (gc *GameContext) FormatContext() string {
 		}
 	}
 
+	// Tell the model when it is going round in circles
+	if gc.Stuck != nil {
+		sb.WriteString("\n=== YOU ARE STUCK ===\n")
+		fmt.Fprintf(&sb, "%s.\n", gc.Stuck.Detail)
+		sb.WriteString("Do something different: take a way you haven't tried lately, or use an object you haven't used.\n")
+	}
+
 	// Include state-aware hints if available
 	if len(gc.Hints) > 0 {
 		sb.WriteString("\n=== SUGGESTED ACTION ===\n")
@@ -245,55 +343,161 @@ func (gc *GameContext) FormatContext() string {
 
 // Player manages AI interactions with the game.
 type Player struct {
-	client       *Client
+	client       LLM
 	history      []Message
 	maxHistory   int
 	showThinking bool
-	systemPrompt string
+	validate     func(command string) error // Checks commands before they are played
+	maxAttempts  int                        // Tries at a command the validator accepts
+	Rejected     int                        // Commands the validator has turned down
+	Fallbacks    int                        // Commands chosen without the model to get unstuck
+	memory       string                     // The notebook as last put in the system prompt
+	memoryTurn   int                        // The turn it was put there
 }
 
-// NewPlayer creates a new AI player.
-func NewPlayer(client *Client, showThinking bool) *Player {
+// NewPlayer creates a new AI player that asks an LLM for its commands.
+func NewPlayer(client LLM, showThinking bool) *Player {
 	return &Player{
 		client:       client,
 		history:      make([]Message, 0),
 		maxHistory:   20,
 		showThinking: showThinking,
-		systemPrompt: defaultSystemPrompt,
 	}
 }
 
-// GetCommand generates a command based on the game context.
+// systemPrompt returns the instructions for the model. A backend that can
+// follow a schema is asked for a structured reply, with its reasoning, until
+// its server turns one down.
+// Otherwise the default prompt asks for nothing but the command, so a model
+// that doesn't reason on its own is asked to when its thinking is to be
+// shown.
+func systemPrompt(client LLM, showThinking bool) string {
+	if _, ok := followsSchema(client); ok {
+		return defaultSystemPrompt + structuredPrompt
+	}
+	if showThinking {
+		return defaultSystemPrompt + thinkingPrompt
+	}
+	return defaultSystemPrompt
+}
+
+const thinkingPrompt = `
+=== SHOWING YOUR THINKING ===
+- Before the command, think briefly about what to do inside <think></think> tags
+- Then give the command alone on the line after </think>
+- Example: <think>It is dark and I have the lamp.</think>
+  LIGHT LAMP
+`
+
+// GetCommand generates a command based on the game context. Cancelling ctx
+// abandons the request to the model.
 // Returns (command, thinking, error).
-func (p *Player) GetCommand(ctx *GameContext) (string, string, error) {
+func (p *Player) GetCommand(ctx context.Context, gc *GameContext) (string, string, error) {
+	return p.GetCommandStream(ctx, gc, nil)
+}
+
+// GetCommandStream is GetCommand, also passing the model's reply so far to
+// onPartial each time more of it arrives. Only LLMs that can stream call
+// onPartial before the reply is complete. If the player has a validator, a
+// command the game wouldn't understand is sent back to the model with the
+// reason, up to maxAttempts times in all; the last command is played
+// regardless.
+func (p *Player) GetCommandStream(ctx context.Context, gc *GameContext, onPartial func(response string)) (string, string, error) {
+	p.refreshMemory(gc)
+
 	// Format the context into a rich prompt
-	contextStr := ctx.FormatContext()
+	prompt := gc.FormatContext()
+
+	// A model that stays stuck is asked more adventurously, then not at all
+	if gc.Stuck != nil {
+		if gc.Stuck.Level >= StuckExplore {
+			if command, ok := exploreCommand(gc); ok {
+				p.Fallbacks++
+				p.history = append(p.history,
+					Message{Role: "user", Content: prompt},
+					Message{Role: "assistant", Content: command})
+				return command, fmt.Sprintf(exploreThinking, command), nil
+			}
+		}
+		if t := gc.Stuck.Temperature(); t > 0 {
+			ctx = WithMinTemperature(ctx, t)
+		}
+	}
 
-	// Add the game context as a user message
+	for attempt := 1; ; attempt++ {
+		command, thinking, err := p.ask(ctx, gc, prompt, onPartial)
+		if err != nil || p.validate == nil || attempt >= p.maxAttempts {
+			return command, thinking, err
+		}
+
+		invalid := p.validate(command)
+		if invalid == nil {
+			return command, thinking, nil
+		}
+		p.Rejected++
+		prompt = fmt.Sprintf(rejectedPrompt, command, invalid)
+	}
+}
+
+// rejectedPrompt tells the model why its command won't work.
+const rejectedPrompt = `The game would not understand %s: %v
+Reply with a different command, using only words from the VALID COMMANDS lists.`
+
+// ask sends a prompt to the model after the conversation so far and returns
+// the command it replies with.
+func (p *Player) ask(ctx context.Context, gc *GameContext, prompt string, onPartial func(response string)) (string, string, error) {
+	// Add the prompt as a user message
 	p.history = append(p.history, Message{
 		Role:    "user",
-		Content: contextStr,
+		Content: prompt,
 	})
 
 	// Trim history if needed (keep pairs to maintain context)
-	if len(p.history) > p.maxHistory*2 {
+	for len(p.history) > p.maxHistory*2 {
 		p.history = p.history[2:]
 	}
 
 	// Build messages with system prompt
 	messages := []Message{
-		{Role: "system", Content: p.systemPrompt},
+		{Role: "system", Content: systemPrompt(p.client, p.showThinking) + p.memory},
 	}
 	messages = append(messages, p.history...)
 
-	// Get response from Ollama
-	response, err := p.client.Chat(messages)
+	// Get response from the model, passing it on as it arrives if wanted
+	var onChunk func(chunk string)
+	if onPartial != nil {
+		var sb strings.Builder
+		onChunk = func(chunk string) {
+			sb.WriteString(chunk)
+			onPartial(sb.String())
+		}
+	}
+
+	var response string
+	var err error
+	schemaLLM, structured := followsSchema(p.client)
+	streamer, streams := p.client.(StreamingLLM)
+	switch {
+	case structured:
+		response, err = schemaLLM.ChatSchema(ctx, messages, commandSchema(gc), onChunk)
+	case streams && onChunk != nil:
+		response, err = streamer.ChatStream(ctx, messages, onChunk)
+	default:
+		response, err = p.client.Chat(ctx, messages)
+	}
 	if err != nil {
 		return "", "", err
 	}
 
-	// Parse the response
-	command, thinking := parseResponse(response)
+	// Parse the response, guessing the command from free text if the model
+	// didn't give a structured reply
+	command, thinking, ok := "", "", false
+	if structured {
+		command, thinking, ok = parseStructured(response, gc)
+	}
+	if !ok {
+		command, thinking = parseResponse(response)
+	}
 
 	// Add the assistant's response to history
 	p.history = append(p.history, Message{
@@ -304,9 +508,45 @@ func (p *Player) GetCommand(ctx *GameContext) (string, string, error) {
 	return command, thinking, nil
 }
 
+// memoryInterval is how many turns the notebook in the system prompt is kept
+// before it is brought up to date. Between times the prompt stays the same,
+// so a server can reuse the work it did on it.
+const memoryInterval = 10
+
+// memoryPrompt puts the game's notebook in the system prompt.
+const memoryPrompt = `
+=== YOUR NOTEBOOK (as of turn %d) ===
+What you have found out so far this game. Older moves drop out of the conversation, so rely on this for where you have been and where things are.
+%s
+`
+
+// refreshMemory puts the game's notebook in the system prompt when there is
+// none there yet, every memoryInterval turns after, and when the game has
+// gone back in time, as after UNDO.
+func (p *Player) refreshMemory(gc *GameContext) {
+	if gc.Memory == "" {
+		return
+	}
+	if p.memory != "" && gc.Turns >= p.memoryTurn && gc.Turns < p.memoryTurn+memoryInterval {
+		return
+	}
+	p.memory = fmt.Sprintf(memoryPrompt, gc.Turns, gc.Memory)
+	p.memoryTurn = gc.Turns
+}
+
+// SetValidator makes the player check each command with validate before
+// playing it, as Game.Validate does, giving the model up to maxAttempts
+// tries in all to find one the game understands.
+func (p *Player) SetValidator(validate func(command string) error, maxAttempts int) {
+	p.validate = validate
+	p.maxAttempts = maxAttempts
+}
+
 // Reset clears the conversation history for a new game.
 func (p *Player) Reset() {
 	p.history = make([]Message, 0)
+	p.memory = ""
+	p.memoryTurn = 0
 }
 
 // parseResponse extracts the command and any thinking from the model's response.
@@ -331,6 +571,47 @@ func parseResponse(response string) (command string, thinking string) {
 	return extractCommand(response), thinking
 }
 
+// ParsePartial splits a reply that is still arriving into the thinking and
+// the command so far, for showing the model at work. It reads structured
+// replies as well as free text. Unlike parseResponse it doesn't tidy the
+// command, which may be incomplete.
+func ParsePartial(response string) (thinking string, command string) {
+	thinking, rest := splitThinking(response)
+	if !strings.HasPrefix(rest, "{") {
+		return thinking, rest
+	}
+
+	if reasoning := strings.TrimSpace(partialField(rest, "reasoning")); reasoning != "" {
+		if thinking != "" {
+			thinking += "\n"
+		}
+		thinking += reasoning
+	}
+	command = strings.TrimSpace(partialField(rest, "verb") + " " + partialField(rest, "object"))
+	return thinking, command
+}
+
+// splitThinking splits a reply, which may be incomplete, into the thinking
+// in <think> tags and the rest.
+func splitThinking(response string) (thinking string, rest string) {
+	start := openThinkRegex.FindStringIndex(response)
+	if start == nil {
+		return "", strings.TrimSpace(response)
+	}
+
+	rest = response[start[1]:]
+	end := closeThinkRegex.FindStringIndex(rest)
+	if end == nil {
+		return strings.TrimSpace(rest), ""
+	}
+	return strings.TrimSpace(rest[:end[0]]), strings.TrimSpace(rest[end[1]:])
+}
+
+var (
+	openThinkRegex  = regexp.MustCompile(`(?i)<think(?:ing)?>`)
+	closeThinkRegex = regexp.MustCompile(`(?i)</think(?:ing)?>`)
+)
+
 // extractCommand finds a valid command in the response.
 func extractCommand(response string) string {
 	response = strings.TrimSpace(response)
@@ -1,6 +1,7 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -153,7 +154,7 @@ func TestGetCommand(t *testing.T) {
 		Turns:          1,
 	}
 
-	cmd, thinking, err := player.GetCommand(ctx)
+	cmd, thinking, err := player.GetCommand(context.Background(), ctx)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -210,7 +211,7 @@ func TestHistoryTrimming(t *testing.T) {
 
 	// Make enough calls to trigger trimming
 	for i := 0; i < 10; i++ {
-		_, _, err := player.GetCommand(ctx)
+		_, _, err := player.GetCommand(context.Background(), ctx)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
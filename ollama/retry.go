@@ -0,0 +1,230 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutError is returned when the model doesn't answer within the client's
+// timeout.
+type TimeoutError struct {
+	URL   string
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request to %s timed out after %v. The model may be loading or is slow to respond.\nTry: -ai-timeout 180 for a longer timeout, or use a smaller/faster model", e.URL, e.After)
+}
+
+// ModelNotFoundError is returned when the server doesn't have the model.
+type ModelNotFoundError struct {
+	Model string
+	Hint  string // How to get the model, if known
+}
+
+func (e *ModelNotFoundError) Error() string {
+	msg := fmt.Sprintf("model '%s' not found", e.Model)
+	if e.Hint != "" {
+		msg += ". " + e.Hint
+	}
+	return msg
+}
+
+// ServerError is returned when the server answers with an error status.
+type ServerError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// ConnectionError is returned when the server can't be reached.
+type ConnectionError struct {
+	URL string
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("failed to send request to %s: %v\nMake sure the server is running", e.URL, e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// RetryPolicy controls how failed requests are retried. Server errors (5xx)
+// and connection failures are retried with exponential backoff; timeouts,
+// missing models and other client errors are not, as they won't get better
+// by asking again.
+type RetryPolicy struct {
+	MaxRetries     int           // Retries after the first attempt; 0 disables retrying
+	InitialBackoff time.Duration // Wait before the first retry
+	MaxBackoff     time.Duration // Longest wait between retries
+}
+
+// DefaultRetryPolicy retries twice, after half a second and then a second.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     2,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     8 * time.Second,
+}
+
+// backoff returns the wait before a retry, doubling from InitialBackoff.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 0; i < retry && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// retryable reports whether a failed request is worth trying again.
+func retryable(err error) bool {
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.StatusCode >= 500
+	}
+	var connErr *ConnectionError
+	return errors.As(err, &connErr)
+}
+
+// newHTTPClient returns the client a chat client shares between requests.
+// Timeouts are applied per request through the context.
+func newHTTPClient() *http.Client {
+	return &http.Client{}
+}
+
+// postWithRetry sends a request built by newRequest, retrying as the policy
+// allows, and returns the body of the first successful response. Each attempt
+// is limited to timeout. A 404 is reported as model not found.
+func postWithRetry(ctx context.Context, client *http.Client, policy RetryPolicy, timeout time.Duration, model string,
+	newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+
+	resp, a, err := openWithRetry(ctx, client, policy, timeout, model, newRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer a.stop()
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, a.failed(ctx, err)
+	}
+	return body, nil
+}
+
+// openWithRetry sends a request built by newRequest, retrying as the policy
+// allows, and returns the first successful response with its body unread.
+// The caller reads the body, calling touch on the attempt whenever some of it
+// arrives, so timeout limits how long the server may stay quiet rather than
+// the whole response. The caller must close the body and stop the attempt.
+func openWithRetry(ctx context.Context, client *http.Client, policy RetryPolicy, timeout time.Duration, model string,
+	newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, *attempt, error) {
+
+	for retry := 0; ; retry++ {
+		resp, a, err := open(ctx, client, timeout, model, newRequest)
+		if err == nil {
+			return resp, a, nil
+		}
+		if retry >= policy.MaxRetries || !retryable(err) {
+			return nil, nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(policy.backoff(retry)):
+		}
+	}
+}
+
+// attempt is one try at a request. It is cancelled when the server has been
+// quiet for longer than its timeout.
+type attempt struct {
+	url      string
+	timeout  time.Duration
+	timer    *time.Timer
+	cancel   context.CancelFunc
+	timedOut atomic.Bool
+}
+
+func newAttempt(ctx context.Context, timeout time.Duration) (context.Context, *attempt) {
+	ctx, cancel := context.WithCancel(ctx)
+	a := &attempt{timeout: timeout, cancel: cancel}
+	if timeout > 0 {
+		a.timer = time.AfterFunc(timeout, func() {
+			a.timedOut.Store(true)
+			cancel()
+		})
+	}
+	return ctx, a
+}
+
+// touch restarts the timeout, as the server has sent something.
+func (a *attempt) touch() {
+	if a.timer != nil && !a.timedOut.Load() {
+		a.timer.Reset(a.timeout)
+	}
+}
+
+// stop releases the attempt's timer and context.
+func (a *attempt) stop() {
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.cancel()
+}
+
+// failed turns an error sending the request or reading the response into a
+// TimeoutError or ConnectionError, or ctx's error if the caller cancelled.
+func (a *attempt) failed(ctx context.Context, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		// Cancelled by the caller, not a failure of the server
+		return ctx.Err()
+	case a.timedOut.Load():
+		return &TimeoutError{URL: a.url, After: a.timeout}
+	}
+	return &ConnectionError{URL: a.url, Err: err}
+}
+
+// open makes one attempt at a request.
+func open(ctx context.Context, client *http.Client, timeout time.Duration, model string,
+	newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, *attempt, error) {
+
+	reqCtx, a := newAttempt(ctx, timeout)
+
+	req, err := newRequest(reqCtx)
+	if err != nil {
+		a.stop()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	a.url = req.URL.String()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		a.stop()
+		return nil, nil, a.failed(ctx, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, a, nil
+	}
+
+	defer a.stop()
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, &ModelNotFoundError{Model: model}
+	}
+	return nil, nil, &ServerError{StatusCode: resp.StatusCode, Body: string(body)}
+}
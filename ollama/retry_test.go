@@ -0,0 +1,170 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer returns a chat server that fails with status the first
+// failures times, then answers NORTH, and a count of the requests it got.
+func flakyServer(t *testing.T, failures int, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte("try again later"))
+			return
+		}
+		w.Write([]byte(`{"message":{"role":"assistant","content":"NORTH"},"done":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// fastRetries is a retry policy that doesn't slow the tests down.
+var fastRetries = RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+func TestChatRetriesServerErrors(t *testing.T) {
+	server, requests := flakyServer(t, 2, http.StatusServiceUnavailable)
+
+	client := NewClient(server.URL, "test-model", 0, 0.1)
+	client.Retry = fastRetries
+	response, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "NORTH" {
+		t.Errorf("expected NORTH, got %s", response)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestChatGivesUpAfterRetries(t *testing.T) {
+	server, requests := flakyServer(t, 10, http.StatusBadGateway)
+
+	client := NewClient(server.URL, "test-model", 0, 0.1)
+	client.Retry = fastRetries
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected a ServerError with status 502, got %v", err)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("expected the first attempt and 3 retries, got %d requests", got)
+	}
+}
+
+func TestChatDoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusBadRequest} {
+		server, requests := flakyServer(t, 10, status)
+
+		client := NewClient(server.URL, "test-model", 0, 0.1)
+		client.Retry = fastRetries
+		if _, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}}); err == nil {
+			t.Errorf("status %d: expected an error", status)
+		}
+		if got := requests.Load(); got != 1 {
+			t.Errorf("status %d: expected 1 request, got %d", status, got)
+		}
+	}
+}
+
+func TestChatRetriesConnectionFailures(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close() // Nothing is listening now
+
+	client := NewClient(url, "test-model", 0, 0.1)
+	client.Retry = RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("expected a ConnectionError, got %v", err)
+	}
+}
+
+func TestChatTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL, "test-model", 20*time.Millisecond, 0.1)
+	client.Retry = fastRetries
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || timeout.After != 20*time.Millisecond {
+		t.Errorf("expected a TimeoutError after 20ms, got %v", err)
+	}
+}
+
+func TestChatCancelled(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	client := NewClient(server.URL, "test-model", time.Minute, 0.1)
+	client.Retry = fastRetries
+	_, err := client.Chat(ctx, []Message{{Role: "user", Content: "test"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestOpenAIChatRetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"NORTH"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "test-model", "", 0, 0.1)
+	client.Retry = fastRetries
+	response, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "NORTH" || requests.Load() != 2 {
+		t.Errorf("expected NORTH after one retry, got %q after %d requests", response, requests.Load())
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for retry, w := range want {
+		if got := policy.backoff(retry); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, w)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package ollama
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Progress tallies what the player has achieved so far in a game, filled in
+// from the game's own tally by whoever is playing it.
+type Progress struct {
+	Score              int
+	Locations          int      // Different locations visited
+	TreasuresSeen      int      // Treasures found, deposited or not
+	TreasuresDeposited int      // Treasures in the building
+	Puzzles            []string // What the player did to solve each puzzle solved
+	Deaths             int
+	Turns              int
+}
+
+// RewardEvents are what an action achieved, or a game's actions in all.
+type RewardEvents struct {
+	Score              int `json:"score"` // Points scored
+	NewLocations       int `json:"new_locations"`
+	TreasuresSeen      int `json:"treasures_seen"`
+	TreasuresDeposited int `json:"treasures_deposited"` // Less any taken back out
+	Puzzles            int `json:"puzzles"`             // Solved, less any undone
+	Deaths             int `json:"deaths"`
+	Turns              int `json:"turns"`
+}
+
+// since works out what was achieved between two tallies, and the puzzles
+// solved.
+func since(before, after Progress) (RewardEvents, []string) {
+	var solved []string
+	puzzles := 0
+	for _, p := range after.Puzzles {
+		if !slices.Contains(before.Puzzles, p) {
+			solved = append(solved, p)
+			puzzles++
+		}
+	}
+	for _, p := range before.Puzzles {
+		if !slices.Contains(after.Puzzles, p) {
+			puzzles--
+		}
+	}
+
+	return RewardEvents{
+		Score:              after.Score - before.Score,
+		NewLocations:       after.Locations - before.Locations,
+		TreasuresSeen:      after.TreasuresSeen - before.TreasuresSeen,
+		TreasuresDeposited: after.TreasuresDeposited - before.TreasuresDeposited,
+		Puzzles:            puzzles,
+		Deaths:             after.Deaths - before.Deaths,
+		Turns:              after.Turns - before.Turns,
+	}, solved
+}
+
+// add adds other's events to e's.
+func (e *RewardEvents) add(other RewardEvents) {
+	e.Score += other.Score
+	e.NewLocations += other.NewLocations
+	e.TreasuresSeen += other.TreasuresSeen
+	e.TreasuresDeposited += other.TreasuresDeposited
+	e.Puzzles += other.Puzzles
+	e.Deaths += other.Deaths
+	e.Turns += other.Turns
+}
+
+// RewardWeights are what each thing the player achieves is worth.
+type RewardWeights struct {
+	Score             float64 `json:"score"`              // Per point scored
+	NewLocation       float64 `json:"new_location"`       // Per location visited for the first time
+	TreasureSeen      float64 `json:"treasure_seen"`      // Per treasure found
+	TreasureDeposited float64 `json:"treasure_deposited"` // Per treasure left in the building
+	Puzzle            float64 `json:"puzzle"`             // Per puzzle solved
+	Death             float64 `json:"death"`
+	Turn              float64 `json:"turn"` // Per turn taken, a penalty for dawdling
+}
+
+// DefaultRewardWeights rewards finding things more than the game's score
+// does, so most moves tell the player something.
+func DefaultRewardWeights() RewardWeights {
+	return RewardWeights{
+		Score:             1,
+		NewLocation:       1,
+		TreasureSeen:      5,
+		TreasureDeposited: 10,
+		Puzzle:            5,
+		Death:             -20,
+		Turn:              -0.1,
+	}
+}
+
+// weights names the weights as ParseRewardWeights reads them, in order.
+func (w *RewardWeights) weights() []struct {
+	name  string
+	value *float64
+} {
+	return []struct {
+		name  string
+		value *float64
+	}{
+		{"score", &w.Score},
+		{"new_location", &w.NewLocation},
+		{"treasure_seen", &w.TreasureSeen},
+		{"treasure_deposited", &w.TreasureDeposited},
+		{"puzzle", &w.Puzzle},
+		{"death", &w.Death},
+		{"turn", &w.Turn},
+	}
+}
+
+// ParseRewardWeights reads weights given as comma-separated name=value
+// pairs, such as "death=-50,turn=0", in place of the defaults.
+func ParseRewardWeights(s string) (RewardWeights, error) {
+	w := DefaultRewardWeights()
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return w, fmt.Errorf("reward weight %q is not name=value", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return w, fmt.Errorf("reward weight %q: %w", pair, err)
+		}
+
+		found := false
+		for _, field := range w.weights() {
+			if field.name == strings.TrimSpace(name) {
+				*field.value = weight
+				found = true
+			}
+		}
+		if !found {
+			return w, fmt.Errorf("unknown reward %q (want %s)", name, w.names())
+		}
+	}
+	return w, nil
+}
+
+// names lists the names of the weights.
+func (w RewardWeights) names() string {
+	var names []string
+	for _, field := range w.weights() {
+		names = append(names, field.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// String formats the weights as ParseRewardWeights reads them.
+func (w RewardWeights) String() string {
+	var pairs []string
+	for _, field := range w.weights() {
+		pairs = append(pairs, field.name+"="+strconv.FormatFloat(*field.value, 'g', -1, 64))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Reward returns what events are worth.
+func (w RewardWeights) Reward(e RewardEvents) float64 {
+	return w.gain(e) + float64(e.Turns)*w.Turn
+}
+
+// gain returns what events are worth, leaving out the turns they took.
+func (w RewardWeights) gain(e RewardEvents) float64 {
+	return float64(e.Score)*w.Score +
+		float64(e.NewLocations)*w.NewLocation +
+		float64(e.TreasuresSeen)*w.TreasureSeen +
+		float64(e.TreasuresDeposited)*w.TreasureDeposited +
+		float64(e.Puzzles)*w.Puzzle +
+		float64(e.Deaths)*w.Death
+}
+
+// Guide describes what the player is rewarded for, for its prompt.
+func (w RewardWeights) Guide() string {
+	var parts []string
+	for _, item := range []struct {
+		what   string
+		weight float64
+	}{
+		{"each point scored", w.Score},
+		{"each new location", w.NewLocation},
+		{"each treasure found", w.TreasureSeen},
+		{"each treasure left in the building", w.TreasureDeposited},
+		{"each puzzle solved", w.Puzzle},
+		{"dying", w.Death},
+		{"each turn", w.Turn},
+	} {
+		if item.weight != 0 {
+			parts = append(parts, fmt.Sprintf("%s %+g", item.what, item.weight))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reasons lists what earned or lost an action its reward, leaving out the
+// turn it took.
+func (w RewardWeights) reasons(e RewardEvents, solved []string) []string {
+	var reasons []string
+	add := func(what string, n int, weight float64) {
+		if n == 0 || weight == 0 {
+			return
+		}
+		if n != 1 && n != -1 {
+			what = fmt.Sprintf("%s x%d", what, n)
+		}
+		reasons = append(reasons, fmt.Sprintf("%s %+g", what, float64(n)*weight))
+	}
+
+	if e.Score != 0 && w.Score == 1 {
+		reasons = append(reasons, fmt.Sprintf("score %+d", e.Score))
+	} else if e.Score != 0 && w.Score != 0 {
+		reasons = append(reasons, fmt.Sprintf("score %+d (%+g)", e.Score, float64(e.Score)*w.Score))
+	}
+	add("new location", e.NewLocations, w.NewLocation)
+	add("treasure found", e.TreasuresSeen, w.TreasureSeen)
+	add("treasure deposited", e.TreasuresDeposited, w.TreasureDeposited)
+	if len(solved) > 0 && e.Puzzles > 0 {
+		add(strings.Join(solved, ", "), e.Puzzles, w.Puzzle)
+	} else {
+		add("puzzle undone", e.Puzzles, w.Puzzle)
+	}
+	add("death", e.Deaths, w.Death)
+	return reasons
+}
@@ -0,0 +1,95 @@
+package ollama
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRewardWeights(t *testing.T) {
+	w, err := ParseRewardWeights("death=-50, turn=0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := DefaultRewardWeights()
+	want.Death, want.Turn = -50, 0
+	if w != want {
+		t.Errorf("expected %+v, got %+v", want, w)
+	}
+
+	if w, err := ParseRewardWeights(want.String()); err != nil || w != want {
+		t.Errorf("expected the weights to read back as written, got %+v, %v", w, err)
+	}
+	if w, err := ParseRewardWeights(""); err != nil || w != DefaultRewardWeights() {
+		t.Errorf("expected the defaults, got %+v, %v", w, err)
+	}
+
+	for _, bad := range []string{"treasure", "death=lots", "luck=5"} {
+		if _, err := ParseRewardWeights(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestObserveRewardsEvents(t *testing.T) {
+	rt := NewRewardTracker()
+	rt.Observe(Progress{Locations: 1})
+
+	// Nothing was started, so there is nothing to reward
+	if rt.Observe(Progress{Locations: 1}) {
+		t.Fatal("expected nothing to be recorded without an action")
+	}
+
+	rt.StartAction(1, "EAST")
+	if !rt.Observe(Progress{Locations: 2, TreasuresSeen: 1, Turns: 1}) {
+		t.Fatal("expected the action to be recorded")
+	}
+	ar := rt.History[0]
+	if ar.Events.NewLocations != 1 || ar.Events.TreasuresSeen != 1 || ar.Outcome != "positive" {
+		t.Errorf("unexpected reward: %+v", ar)
+	}
+	if want := 1 + 5 - 0.1; ar.Reward != want {
+		t.Errorf("expected a reward of %v, got %v", want, ar.Reward)
+	}
+
+	// A move that finds nothing costs the turn but isn't to be avoided
+	rt.StartAction(2, "WEST")
+	rt.Observe(Progress{Locations: 2, TreasuresSeen: 1, Turns: 2})
+	if ar := rt.History[1]; ar.Outcome != "neutral" || ar.Reward != -0.1 {
+		t.Errorf("expected a neutral move costing its turn, got %+v", ar)
+	}
+
+	// Solving a puzzle names it
+	rt.StartAction(1, "UNLOCK GRATE")
+	rt.Observe(Progress{Locations: 2, TreasuresSeen: 1, Turns: 3, Puzzles: []string{"unlocked the grate"}})
+	feedback := rt.GetFeedback()
+	if !strings.Contains(feedback[2], "unlocked the grate +5") {
+		t.Errorf("expected the feedback to name the puzzle, got %q", feedback[2])
+	}
+
+	// Dying is told by the death count, whether or not the game is over
+	rt.StartAction(1, "JUMP")
+	rt.Observe(Progress{Score: -10, Locations: 2, TreasuresSeen: 1, Turns: 4, Puzzles: []string{"unlocked the grate"}, Deaths: 1})
+	if ar := rt.History[3]; ar.Outcome != "death" || ar.Events.Deaths != 1 {
+		t.Errorf("expected a death, got %+v", ar)
+	}
+
+	if rt.Totals.NewLocations != 1 || rt.Totals.Puzzles != 1 || rt.Totals.Deaths != 1 || rt.Totals.Turns != 4 {
+		t.Errorf("unexpected totals: %+v", rt.Totals)
+	}
+	if want := 5.9 - 0.1 + 4.9 - 10 - 20 - 0.1; rt.Total < want-1e-9 || rt.Total > want+1e-9 {
+		t.Errorf("expected a total of %v, got %v", want, rt.Total)
+	}
+}
+
+func TestRewardGuideInPrompt(t *testing.T) {
+	rt := NewRewardTracker()
+	rt.RecordAction("GET LAMP", 0, 5, false)
+
+	gc := &GameContext{RewardFeedback: rt.GetFeedback(), RewardGuide: rt.Weights.Guide()}
+	prompt := gc.FormatContext()
+	for _, want := range []string{"Rewards: each point scored +1", "each new location +1", "dying -20", "GET LAMP: +5 (score +5). Good move!"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected the prompt to contain %q:\n%s", want, prompt)
+		}
+	}
+}
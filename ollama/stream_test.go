@@ -0,0 +1,186 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamServer returns a chat server that streams chunks as Ollama does, one
+// JSON object per line, waiting pause between them.
+func streamServer(t *testing.T, pause time.Duration, chunks ...ChatResponse) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("expected a streaming request")
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, chunk := range chunks {
+			time.Sleep(pause)
+			json.NewEncoder(w).Encode(chunk)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func content(s string) ChatResponse {
+	return ChatResponse{Message: Message{Role: "assistant", Content: s}}
+}
+
+func thought(s string) ChatResponse {
+	return ChatResponse{Message: Message{Role: "assistant", Thinking: s}}
+}
+
+var done = ChatResponse{Done: true}
+
+func TestChatStream(t *testing.T) {
+	server := streamServer(t, 0, content("GET"), content(" LAMP"), done)
+
+	client := NewClient(server.URL, "test-model", 0, 0.1)
+	var chunks []string
+	response, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "test"}}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "GET LAMP" {
+		t.Errorf("expected GET LAMP, got %q", response)
+	}
+	if strings.Join(chunks, "|") != "GET| LAMP" {
+		t.Errorf("expected the chunks as they arrived, got %q", chunks)
+	}
+}
+
+func TestChatStreamThinking(t *testing.T) {
+	server := streamServer(t, 0, thought("It is"), thought(" dark."), content("LIGHT LAMP"), done)
+
+	client := NewClient(server.URL, "test-model", 0, 0.1)
+	response, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "test"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	command, thinking := parseResponse(response)
+	if command != "LIGHT LAMP" || thinking != "It is dark." {
+		t.Errorf("expected LIGHT LAMP with thinking, got %q, %q", command, thinking)
+	}
+}
+
+func TestChatStreamErrors(t *testing.T) {
+	server := streamServer(t, 0, content("GET"), ChatResponse{Error: "model crashed"})
+	client := NewClient(server.URL, "test-model", 0, 0.1)
+	_, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "test"}}, nil)
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Body != "model crashed" {
+		t.Errorf("expected a ServerError for the failed stream, got %v", err)
+	}
+
+	server = streamServer(t, 0, content("GET"))
+	client = NewClient(server.URL, "test-model", 0, 0.1)
+	_, err = client.ChatStream(context.Background(), []Message{{Role: "user", Content: "test"}}, nil)
+	var connErr *ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("expected a ConnectionError for a stream cut short, got %v", err)
+	}
+}
+
+func TestChatStreamTimeoutIsBetweenChunks(t *testing.T) {
+	// The whole reply takes longer than the timeout, but no gap between
+	// chunks does
+	server := streamServer(t, 20*time.Millisecond, content("N"), content("O"), content("R"), content("TH"), done)
+
+	client := NewClient(server.URL, "test-model", 50*time.Millisecond, 0.1)
+	response, err := client.ChatStream(context.Background(), []Message{{Role: "user", Content: "test"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "NORTH" {
+		t.Errorf("expected NORTH, got %q", response)
+	}
+
+	server = streamServer(t, 100*time.Millisecond, content("N"), done)
+	client = NewClient(server.URL, "test-model", 50*time.Millisecond, 0.1)
+	_, err = client.ChatStream(context.Background(), []Message{{Role: "user", Content: "test"}}, nil)
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) {
+		t.Errorf("expected a TimeoutError, got %v", err)
+	}
+}
+
+func TestChatThinkingField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{
+			Message: Message{Role: "assistant", Content: "NORTH", Thinking: "The road goes north."},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model", 0, 0.1)
+	response, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "test"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "<think>The road goes north.</think>\nNORTH" {
+		t.Errorf("expected the thinking in tags, got %q", response)
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	tests := []struct {
+		response string
+		thinking string
+		command  string
+	}{
+		{"", "", ""},
+		{"NOR", "", "NOR"},
+		{"<think>It is da", "It is da", ""},
+		{"<thinking>It is dark.</thinking>\nLIGHT", "It is dark.", "LIGHT"},
+		{"<think>Done.</think>", "Done.", ""},
+	}
+
+	for _, tt := range tests {
+		thinking, command := ParsePartial(tt.response)
+		if thinking != tt.thinking || command != tt.command {
+			t.Errorf("ParsePartial(%q) = %q, %q, want %q, %q", tt.response, thinking, command, tt.thinking, tt.command)
+		}
+	}
+}
+
+func TestPlayerStreamsCommand(t *testing.T) {
+	llm := NewScriptedLLM("<think>I need light.</think>\nGET LAMP")
+	player := NewPlayer(llm, true)
+
+	var partials []string
+	cmd, thinking, err := player.GetCommandStream(context.Background(), &GameContext{GameOutput: "test"}, func(response string) {
+		partials = append(partials, response)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd != "GET LAMP" || thinking != "I need light." {
+		t.Errorf("expected GET LAMP with thinking, got %q, %q", cmd, thinking)
+	}
+	if len(partials) < 2 || partials[len(partials)-1] != "<think>I need light.</think>\nGET LAMP" {
+		t.Errorf("expected the reply to build up, got %q", partials)
+	}
+
+	if !strings.Contains(llm.Calls()[0][0].Content, "<think>") {
+		t.Error("expected the system prompt to ask for thinking when it is shown")
+	}
+}
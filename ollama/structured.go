@@ -0,0 +1,151 @@
+package ollama
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// commandReply is the structured reply the player asks for from backends
+// that can follow a schema.
+type commandReply struct {
+	Reasoning string `json:"reasoning"`
+	Verb      string `json:"verb"`
+	Object    string `json:"object"`
+}
+
+const structuredPrompt = `
+=== REPLY FORMAT ===
+- Instead of the bare command, reply with a JSON object with the fields "reasoning", "verb" and "object"
+- "reasoning" is a sentence or two about what to do and why
+- "verb" is the first word of the command: an action or a direction
+- "object" is the second word, or "" for a one word command
+- Example: {"reasoning": "It is dark and I have the lamp.", "verb": "LIGHT", "object": "LAMP"}
+`
+
+// commandSchema returns the JSON schema for a commandReply. When the game's
+// vocabulary is known the words are limited to it, so a backend that
+// enforces the schema can't reply with a word the game doesn't know.
+func commandSchema(gc *GameContext) json.RawMessage {
+	word := func(blank bool) map[string]any {
+		property := map[string]any{"type": "string"}
+		if len(gc.Vocabulary) > 0 {
+			var words []string
+			if blank {
+				words = append(words, "")
+			}
+			for _, w := range gc.Vocabulary {
+				words = append(words, strings.ToUpper(w))
+			}
+			property["enum"] = words
+		}
+		return property
+	}
+
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"reasoning": map[string]any{"type": "string"},
+			"verb":      word(false),
+			"object":    word(true),
+		},
+		"required":             []string{"reasoning", "verb", "object"},
+		"additionalProperties": false,
+	}
+
+	data, _ := json.Marshal(schema)
+	return data
+}
+
+// parseStructured reads a structured reply. It reports false if the reply
+// isn't one, as when the model ignored the format, so the caller can fall
+// back to parseResponse. Reasoning the model gave in <think> tags as well is
+// kept with the reply's own.
+func parseStructured(response string, gc *GameContext) (command string, thinking string, ok bool) {
+	thinking, text := splitThinking(response)
+
+	var reply commandReply
+	if err := json.Unmarshal([]byte(text), &reply); err != nil {
+		return "", "", false
+	}
+	if strings.TrimSpace(reply.Verb) == "" {
+		return "", "", false
+	}
+
+	if reasoning := strings.TrimSpace(reply.Reasoning); reasoning != "" {
+		if thinking != "" {
+			thinking += "\n"
+		}
+		thinking += reasoning
+	}
+	return reply.command(gc.Vocabulary), thinking, true
+}
+
+// command checks the reply's words against the vocabulary and returns the
+// command to play. A word the game doesn't know is dropped, leaving the one
+// it does; if it knows neither, the words are played as given so the game
+// can say so.
+func (r commandReply) command(vocabulary []string) string {
+	verb := firstWord(r.Verb)
+	object := firstWord(r.Object)
+	if len(vocabulary) == 0 {
+		return strings.TrimSpace(verb + " " + object)
+	}
+
+	known := make(map[string]bool, len(vocabulary))
+	for _, w := range vocabulary {
+		known[strings.ToUpper(w)] = true
+	}
+
+	switch {
+	case known[verb] && (object == "" || known[object]):
+		return strings.TrimSpace(verb + " " + object)
+	case known[verb]:
+		return verb
+	case known[object]:
+		return object
+	}
+	return strings.TrimSpace(verb + " " + object)
+}
+
+// firstWord returns the first word of s, upper cased.
+func firstWord(s string) string {
+	fields := strings.Fields(strings.ToUpper(s))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// partialField finds a string field in a JSON reply that is still arriving
+// and returns as much of its value as has arrived.
+func partialField(text, field string) string {
+	loc := regexp.MustCompile(`"` + field + `"\s*:\s*"`).FindStringIndex(text)
+	if loc == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	rest := text[loc[1]:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '"':
+			return sb.String()
+		case '\\':
+			if i+1 < len(rest) {
+				i++
+				switch rest[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					sb.WriteByte(rest[i])
+				}
+			}
+		default:
+			sb.WriteByte(rest[i])
+		}
+	}
+	return sb.String()
+}
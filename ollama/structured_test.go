@@ -0,0 +1,180 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testVocabulary = []string{"get", "drop", "light", "lamp", "keys", "north", "xyzzy"}
+
+func TestCommandSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(commandSchema(&GameContext{Vocabulary: testVocabulary}), &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+
+	if len(schema.Required) != 3 {
+		t.Errorf("expected reasoning, verb and object to be required, got %v", schema.Required)
+	}
+	if verbs := schema.Properties["verb"].Enum; !slices.Contains(verbs, "LAMP") || slices.Contains(verbs, "") {
+		t.Errorf("expected the verb limited to the vocabulary, got %v", verbs)
+	}
+	if objects := schema.Properties["object"].Enum; !slices.Contains(objects, "") {
+		t.Errorf("expected the object to allow a one word command, got %v", objects)
+	}
+
+	json.Unmarshal(commandSchema(&GameContext{}), &schema)
+	if schema.Properties["verb"].Enum != nil {
+		t.Error("expected no word list without a vocabulary")
+	}
+}
+
+func TestParseStructured(t *testing.T) {
+	gc := &GameContext{Vocabulary: testVocabulary}
+	tests := []struct {
+		response string
+		command  string
+		thinking string
+		ok       bool
+	}{
+		{`{"reasoning":"It is dark.","verb":"light","object":"lamp"}`, "LIGHT LAMP", "It is dark.", true},
+		{`{"reasoning":"","verb":"NORTH","object":""}`, "NORTH", "", true},
+		{`<think>Hmm.</think>{"reasoning":"Magic.","verb":"XYZZY","object":""}`, "XYZZY", "Hmm.\nMagic.", true},
+		{`{"reasoning":"","verb":"GET","object":"SHINY"}`, "GET", "", true},
+		{`{"reasoning":"","verb":"GO","object":"NORTH"}`, "NORTH", "", true},
+		{`{"reasoning":"","verb":"FROB","object":"WIDGET"}`, "FROB WIDGET", "", true},
+		{`I THINK I will GET LAMP`, "", "", false},
+		{`{"reasoning":"No idea.","verb":"","object":""}`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		command, thinking, ok := parseStructured(tt.response, gc)
+		if command != tt.command || thinking != tt.thinking || ok != tt.ok {
+			t.Errorf("parseStructured(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.response, command, thinking, ok, tt.command, tt.thinking, tt.ok)
+		}
+	}
+}
+
+func TestParsePartialStructured(t *testing.T) {
+	tests := []struct {
+		response string
+		thinking string
+		command  string
+	}{
+		{`{"reasoning":"It is da`, "It is da", ""},
+		{`{"reasoning":"Say \"hi\".","verb":"LIG`, `Say "hi".`, "LIG"},
+		{`{"reasoning":"Dark.","verb":"LIGHT","object":"LAMP"}`, "Dark.", "LIGHT LAMP"},
+	}
+
+	for _, tt := range tests {
+		thinking, command := ParsePartial(tt.response)
+		if thinking != tt.thinking || command != tt.command {
+			t.Errorf("ParsePartial(%q) = %q, %q, want %q, %q", tt.response, thinking, command, tt.thinking, tt.command)
+		}
+	}
+}
+
+func TestPlayerStructuredReply(t *testing.T) {
+	var got ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Message: Message{Role: "assistant", Content: `{"reasoning":"I need light.","verb":"GET","object":"LAMP"}`},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
+
+	player := NewPlayer(NewClient(server.URL, "test-model", 0, 0.1), false)
+	cmd, thinking, err := player.GetCommand(context.Background(), &GameContext{Vocabulary: testVocabulary})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd != "GET LAMP" || thinking != "I need light." {
+		t.Errorf("expected GET LAMP with the reasoning, got %q, %q", cmd, thinking)
+	}
+	if len(got.Format) == 0 {
+		t.Error("expected the request to carry the schema as its format")
+	}
+}
+
+func TestOpenAIChatSchema(t *testing.T) {
+	server, got, _ := openAIServer(t, `{"reasoning":"","verb":"NORTH","object":""}`, "")
+
+	client := NewOpenAIClient(server.URL, "test-model", "", 0, 0.1)
+	var chunks []string
+	response, err := client.ChatSchema(context.Background(), []Message{{Role: "user", Content: "test"}},
+		commandSchema(&GameContext{}), func(chunk string) { chunks = append(chunks, chunk) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.ResponseFormat == nil || got.ResponseFormat.Type != "json_schema" || len(got.ResponseFormat.JSONSchema.Schema) == 0 {
+		t.Errorf("expected a json_schema response format, got %+v", got.ResponseFormat)
+	}
+	if len(chunks) != 1 || chunks[0] != response {
+		t.Errorf("expected the whole reply as one chunk, got %q", chunks)
+	}
+}
+
+func TestOpenAIChatSchemaRefused(t *testing.T) {
+	var formats int
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OpenAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.ResponseFormat != nil {
+			formats++
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Unsupported parameter: 'response_format'"}}`))
+			return
+		}
+		prompts = append(prompts, req.Messages[0].Content)
+		w.Write([]byte(`{"model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":"NORTH"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cassette, err := CreateCassette(filepath.Join(t.TempDir(), "game.cassette"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cassette.Close()
+
+	client := NewOpenAIClient(server.URL, "test-model", "", 0, 0.1)
+	outputs := []string{"You are at the end of a road.", "You are in a forest.", "You are in a valley."}
+	commands, err := playContexts(t, NewRecorder(client, cassette, 1), outputs)
+	if err != nil {
+		t.Fatalf("expected the request to be sent again without the format, got %v", err)
+	}
+	if !slices.Equal(commands, []string{"NORTH", "NORTH", "NORTH"}) {
+		t.Errorf("expected the free text replies to be read, got %v", commands)
+	}
+
+	// The format is tried once, then the player asks for free text
+	if formats != 1 || !client.SchemaRefused() {
+		t.Errorf("expected the format to be tried once, got %d tries", formats)
+	}
+	if len(prompts) != 3 || !strings.Contains(prompts[0], "REPLY FORMAT") || strings.Contains(prompts[2], "REPLY FORMAT") {
+		t.Errorf("expected the structured prompt to be dropped after the first turn")
+	}
+
+	// The replay asks as the recording did
+	commands, err = playContexts(t, NewReplayLLM(cassette, 1), outputs)
+	if err != nil || len(commands) != 3 {
+		t.Errorf("expected the replay to find every reply, got %v, %v", commands, err)
+	}
+}
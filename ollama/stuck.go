@@ -0,0 +1,178 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andrewsjg/goAdventure/telemetry"
+)
+
+// How the reward tracker decides the player is stuck, and how hard it pushes
+// to get it going again.
+const (
+	stuckRepeats       = 3  // Times the same command at the same place is a cycle
+	stuckNoProgress    = 20 // Actions without going somewhere new or scoring
+	stuckEscalateEvery = 3  // Actions stuck before each push harder
+)
+
+// How hard the player is pushed while it is stuck.
+const (
+	StuckFeedback = 1 + iota // It is told it is going round in circles
+	StuckWarmer              // It is also asked more adventurously
+	StuckHot                 // It is asked more adventurously still
+	StuckExplore             // It isn't asked; a way it hasn't tried is taken
+)
+
+// stuckTemperatures are the least temperatures a stuck player is asked at.
+var stuckTemperatures = map[int]float64{
+	StuckWarmer: 0.7,
+	StuckHot:    1.0,
+}
+
+// StuckEvent describes the player going round in circles.
+type StuckEvent struct {
+	Action int      // Actions recorded when the player was found to be stuck
+	Kind   string   // "cycle" or "no progress"
+	Detail string   // What the player has been doing, to tell it
+	Level  int      // How hard the player is being pushed, StuckFeedback to StuckExplore
+	Recent []string // Actions since the player last got anywhere
+}
+
+// Temperature returns the least temperature the player should be asked at,
+// or 0 to leave it be.
+func (e *StuckEvent) Temperature() float64 {
+	return stuckTemperatures[e.Level]
+}
+
+// CheckStuck looks at the actions since the player last went somewhere new
+// or scored for a command it keeps giving at the same place, or for too long
+// without getting anywhere. It returns how stuck the player is and whether
+// that is news: the player has just got stuck or is being pushed harder.
+// Call it once after each recorded action.
+func (rt *RewardTracker) CheckStuck() (StuckEvent, bool) {
+	event, stuck := rt.detectStuck()
+	if !stuck {
+		rt.stuckFor = -1
+		return StuckEvent{}, false
+	}
+
+	if rt.stuckFor < 0 || len(rt.StuckEvents) == 0 {
+		rt.stuckFor = 0
+		event.Level = StuckFeedback
+		rt.StuckEvents = append(rt.StuckEvents, event)
+		return event, true
+	}
+
+	rt.stuckFor++
+	last := &rt.StuckEvents[len(rt.StuckEvents)-1]
+	event.Action = last.Action
+	event.Level = min(StuckFeedback+rt.stuckFor/stuckEscalateEvery, StuckExplore)
+	escalated := event.Level > last.Level
+	*last = event
+	return event, escalated
+}
+
+// Stuck returns how stuck the player is, or nil if it isn't.
+func (rt *RewardTracker) Stuck() *StuckEvent {
+	if rt.stuckFor < 0 || len(rt.StuckEvents) == 0 {
+		return nil
+	}
+	event := rt.StuckEvents[len(rt.StuckEvents)-1]
+	return &event
+}
+
+// detectStuck reports whether the recent actions show the player is stuck.
+func (rt *RewardTracker) detectStuck() (StuckEvent, bool) {
+	start := 0
+	for i, ar := range rt.History {
+		if ar.Progress {
+			start = i + 1
+		}
+	}
+	recent := make([]string, 0, len(rt.History)-start)
+	for _, ar := range rt.History[start:] {
+		recent = append(recent, ar.Action)
+	}
+
+	type place struct {
+		location int
+		action   string
+	}
+	counts := make(map[place]int)
+	for _, ar := range rt.History[start:] {
+		p := place{ar.Location, strings.ToUpper(ar.Action)}
+		counts[p]++
+		if counts[p] >= stuckRepeats {
+			return StuckEvent{
+				Action: rt.Actions,
+				Kind:   "cycle",
+				Detail: fmt.Sprintf("You have tried %s %d times in the same place without getting anywhere", p.action, counts[p]),
+				Recent: recent,
+			}, true
+		}
+	}
+
+	if rt.sinceProgress >= stuckNoProgress {
+		return StuckEvent{
+			Action: rt.Actions,
+			Kind:   "no progress",
+			Detail: fmt.Sprintf("You have taken %d actions without finding anywhere new or scoring", rt.sinceProgress),
+			Recent: recent,
+		}, true
+	}
+
+	return StuckEvent{}, false
+}
+
+// exploreThinking is the thinking given for a command chosen to get unstuck.
+const exploreThinking = "Stuck going round in circles, so trying %s, a way not taken lately."
+
+// exploreCommand picks the way out of the player's location it has taken
+// least recently, for a player that is still stuck after being asked to do
+// something different.
+func exploreCommand(gc *GameContext) (string, bool) {
+	if len(gc.ValidDirections) == 0 {
+		return "", false
+	}
+
+	best, bestUsed := "", len(gc.Stuck.Recent)
+	for _, dir := range gc.ValidDirections {
+		used := -1
+		for i, action := range gc.Stuck.Recent {
+			if strings.EqualFold(action, dir) {
+				used = i
+			}
+		}
+		if best == "" || used < bestUsed {
+			best, bestUsed = dir, used
+		}
+	}
+	return strings.ToUpper(best), true
+}
+
+// ObserveTurn observes the game's progress as the player waits for its next
+// command, as Observe does. When that records an action that changes how
+// stuck the player is, the change is traced on the span in ctx.
+func (rt *RewardTracker) ObserveTurn(ctx context.Context, now Progress) {
+	if !rt.Observe(now) {
+		return
+	}
+	if event, changed := rt.CheckStuck(); changed {
+		TraceStuck(ctx, event)
+	}
+}
+
+// TraceStuck adds an event to the span in ctx, if there is one, saying the
+// player is stuck.
+func TraceStuck(ctx context.Context, event StuckEvent) {
+	span := telemetry.SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	telemetry.AddGameEvent(span, "ai.stuck",
+		telemetry.AttrStuckKind.String(event.Kind),
+		telemetry.AttrStuckLevel.Int(event.Level),
+		telemetry.AttrStuckDetail.String(event.Detail),
+	)
+}
@@ -0,0 +1,177 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCheckStuckCycle(t *testing.T) {
+	rt := NewRewardTracker()
+
+	rt.RecordActionAt(1, "EAST", 0, 0, false)
+	if _, changed := rt.CheckStuck(); changed || rt.Stuck() != nil {
+		t.Fatal("expected the first action not to be stuck")
+	}
+
+	// Going back and forth between two places already seen
+	for _, loc := range []int{3, 1, 3, 1, 3, 1} {
+		action := "WEST"
+		if loc == 1 {
+			action = "EAST"
+		}
+		rt.RecordActionAt(loc, action, 0, 0, false)
+		rt.CheckStuck()
+	}
+
+	stuck := rt.Stuck()
+	if stuck == nil || stuck.Kind != "cycle" || stuck.Level != StuckFeedback {
+		t.Fatalf("expected a cycle at the first level, got %+v", stuck)
+	}
+	if !strings.Contains(stuck.Detail, "EAST 3 times") {
+		t.Errorf("expected the detail to name the repeated command, got %q", stuck.Detail)
+	}
+	if len(rt.StuckEvents) != 1 {
+		t.Errorf("expected one stuck event, got %d", len(rt.StuckEvents))
+	}
+
+	// Scoring gets the player going again
+	rt.RecordActionAt(3, "GET LAMP", 0, 5, false)
+	if _, changed := rt.CheckStuck(); changed || rt.Stuck() != nil {
+		t.Error("expected scoring to end the cycle")
+	}
+}
+
+func TestCheckStuckNoProgress(t *testing.T) {
+	rt := NewRewardTracker()
+	rt.RecordActionAt(1, "LOOK", 0, 0, false)
+
+	for i := range stuckNoProgress - 1 {
+		rt.RecordActionAt(1, fmt.Sprintf("LOOK %d", i), 0, 0, false)
+		if _, changed := rt.CheckStuck(); changed {
+			t.Fatalf("expected not to be stuck after %d actions", i+1)
+		}
+	}
+	rt.RecordActionAt(1, "LOOK AGAIN", 0, 0, false)
+
+	event, changed := rt.CheckStuck()
+	if !changed || event.Kind != "no progress" {
+		t.Fatalf("expected no progress to be reported, got %+v, %v", event, changed)
+	}
+
+	// Somewhere new is progress
+	rt.RecordActionAt(5, "NORTH", 0, 0, false)
+	if rt.CheckStuck(); rt.Stuck() != nil {
+		t.Error("expected a new place to end being stuck")
+	}
+}
+
+func TestObserveTurnTracesStuck(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "location")
+
+	rt := NewRewardTracker()
+	rt.ObserveTurn(ctx, Progress{})
+	for i := range stuckNoProgress + 1 {
+		rt.StartAction(1, fmt.Sprintf("LOOK %d", i))
+		rt.ObserveTurn(ctx, Progress{})
+	}
+	span.End()
+
+	if rt.Stuck() == nil {
+		t.Fatal("expected the player to be stuck")
+	}
+	var traced int
+	for _, event := range recorder.Ended()[0].Events() {
+		if event.Name == "ai.stuck" {
+			traced++
+		}
+	}
+	if traced == 0 || traced != len(rt.StuckEvents) {
+		t.Errorf("expected each of the %d stuck events to be traced, got %d", len(rt.StuckEvents), traced)
+	}
+}
+
+func TestCheckStuckEscalates(t *testing.T) {
+	rt := NewRewardTracker()
+	rt.RecordActionAt(1, "XYZZY", 0, 0, false)
+
+	var levels []int
+	for range 3 + 3*stuckEscalateEvery {
+		rt.RecordActionAt(1, "XYZZY", 0, 0, false)
+		if event, changed := rt.CheckStuck(); changed {
+			levels = append(levels, event.Level)
+		}
+	}
+
+	want := []int{StuckFeedback, StuckWarmer, StuckHot, StuckExplore}
+	if len(levels) != len(want) {
+		t.Fatalf("expected levels %v, got %v", want, levels)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("expected levels %v, got %v", want, levels)
+		}
+	}
+	if len(rt.StuckEvents) != 1 || rt.StuckEvents[0].Level != StuckExplore {
+		t.Errorf("expected one event at its worst level, got %+v", rt.StuckEvents)
+	}
+	if (&StuckEvent{Level: StuckWarmer}).Temperature() <= 0 || (&StuckEvent{Level: StuckFeedback}).Temperature() != 0 {
+		t.Error("expected only the warmer levels to raise the temperature")
+	}
+}
+
+func TestPlayerExploresWhenStuck(t *testing.T) {
+	llm := NewScriptedLLM("XYZZY")
+	player := NewPlayer(llm, false)
+	gc := &GameContext{
+		GameOutput:      "You are in a maze of twisty little passages, all alike.",
+		ValidDirections: []string{"north", "south", "east"},
+		Stuck:           &StuckEvent{Kind: "cycle", Detail: "You have tried NORTH 3 times", Level: StuckExplore, Recent: []string{"NORTH", "EAST", "NORTH"}},
+	}
+
+	cmd, thinking, err := player.GetCommand(context.Background(), gc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd != "SOUTH" {
+		t.Errorf("expected the way not tried lately, got %s", cmd)
+	}
+	if thinking == "" || player.Fallbacks != 1 {
+		t.Errorf("expected the fallback to be explained and counted, got %q, %d", thinking, player.Fallbacks)
+	}
+
+	// Below the last level the model is still asked, and told it is stuck
+	gc.Stuck.Level = StuckHot
+	if cmd, _, _ := player.GetCommand(context.Background(), gc); cmd != "XYZZY" {
+		t.Errorf("expected the model's command, got %s", cmd)
+	}
+	if prompt := gc.FormatContext(); !strings.Contains(prompt, "YOU ARE STUCK") || !strings.Contains(prompt, "tried NORTH 3 times") {
+		t.Errorf("expected the prompt to say the player is stuck:\n%s", prompt)
+	}
+}
+
+func TestWithMinTemperature(t *testing.T) {
+	server, got, _ := openAIServer(t, "NORTH", "")
+	client := NewOpenAIClient(server.URL, "test-model", "", 0, 0.3)
+	messages := []Message{{Role: "user", Content: "You are at the end of a road."}}
+
+	if _, err := client.Chat(WithMinTemperature(context.Background(), 0.9), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Temperature != 0.9 {
+		t.Errorf("expected the temperature to be raised to 0.9, got %v", got.Temperature)
+	}
+
+	if _, err := client.Chat(WithMinTemperature(context.Background(), 0.1), messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Temperature != 0.3 {
+		t.Errorf("expected the client's own temperature, got %v", got.Temperature)
+	}
+}
@@ -125,6 +125,9 @@ var (
 	AttrInventory    = attribute.Key("game.inventory")
 	AttrMovedFrom    = attribute.Key("game.moved_from")
 	AttrMovedTo      = attribute.Key("game.moved_to")
+	AttrStuckKind    = attribute.Key("ai.stuck.kind")
+	AttrStuckLevel   = attribute.Key("ai.stuck.level")
+	AttrStuckDetail  = attribute.Key("ai.stuck.detail")
 )
 
 // AddGameEvent adds an event to the current span
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/andrewsjg/goAdventure/advent"
-	"github.com/andrewsjg/goAdventure/dungeon"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -89,18 +88,16 @@ func highlightLine(line string, game *advent.Game) string {
 	}
 
 	// Highlight object names within the line
-	return highlightObjects(line)
+	return highlightObjects(line, game)
 }
 
 // highlightObjects highlights known object names in the text
-func highlightObjects(line string) string {
+func highlightObjects(line string, game *advent.Game) string {
 	// Build a map of object words to highlight
 	objectWords := make(map[string]bool)
-	for i := 1; i < len(dungeon.Objects); i++ {
-		for _, word := range dungeon.Objects[i].Words.Strs {
-			if word != "" && len(word) > 2 {
-				objectWords[strings.ToLower(word)] = true
-			}
+	for _, word := range game.GetObjectWords() {
+		if len(word) > 2 {
+			objectWords[word] = true
 		}
 	}
 
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -36,6 +37,10 @@ type model struct {
 	// Pinned location description
 	locationDesc string
 
+	// Timeline view state
+	timelineMode   bool // True while the timeline pane is open
+	timelineCursor int  // Selected row: turns first, then branches
+
 	// AI player fields
 	aiPlayer      *ollama.Player
 	aiEnabled     bool
@@ -43,9 +48,12 @@ type model struct {
 	showThinking  bool
 	aiThinking    string // Last AI reasoning (for display)
 	aiIsThinking  bool   // True when waiting for AI response
+	aiPartial     string // The AI's reply so far, while it streams in
+	aiStream      <-chan tea.Msg
 	aiSpinner     spinner.Model
+	aiCtx         context.Context // Cancelled on quit to abandon a pending AI request
+	aiCancel      context.CancelFunc
 	rewardTracker *ollama.RewardTracker // Tracks action rewards for AI feedback
-	lastScore     int                   // Score before last AI action
 }
 
 const maxMoveHistory = 4
@@ -84,6 +92,8 @@ func initialModel(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *oll
 	sp.Spinner = spinner.Dot
 	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
+	aiCtx, aiCancel := context.WithCancel(context.Background())
+
 	return model{
 		input:          ti,
 		gameOutput:     vp,
@@ -104,11 +114,20 @@ func initialModel(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *oll
 		aiThinking:     "",
 		aiIsThinking:   false,
 		aiSpinner:      sp,
+		aiCtx:          aiCtx,
+		aiCancel:       aiCancel,
 		rewardTracker:  rewardTracker,
-		lastScore:      0,
 	}
 }
 
+// quit stops the program, abandoning any request the AI is waiting on.
+func (m model) quit() tea.Cmd {
+	if m.aiCancel != nil {
+		m.aiCancel()
+	}
+	return tea.Quit
+}
+
 func NewAdventure(game *advent.Game, aiPlayer *ollama.Player, rewardTracker *ollama.RewardTracker, showThinking bool, aiDelay time.Duration) *tea.Program {
 	m := initialModel(game, aiPlayer, rewardTracker, showThinking, aiDelay)
 	p := tea.NewProgram(m, tea.WithAltScreen())
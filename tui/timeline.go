@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const timelineKey = "ctrl+t"
+const maxTimelineRows = 15
+
+// timelineLen returns the number of selectable rows in the timeline:
+// the turns on the current line followed by the abandoned branches.
+func (m model) timelineLen() int {
+	return len(m.game.Snapshots) + len(m.game.Branches)
+}
+
+// toggleTimeline opens or closes the timeline, selecting the latest turn on open.
+func (m model) toggleTimeline() model {
+	m.timelineMode = !m.timelineMode
+	m.timelineCursor = len(m.game.Snapshots) - 1
+	if m.timelineCursor < 0 {
+		m.timelineCursor = 0
+	}
+	return m
+}
+
+// updateTimeline handles key presses while the timeline is open.
+func (m model) updateTimeline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case timelineKey, "esc":
+		m.timelineMode = false
+
+	case "up", "k":
+		if m.timelineCursor > 0 {
+			m.timelineCursor--
+		}
+
+	case "down", "j":
+		if m.timelineCursor < m.timelineLen()-1 {
+			m.timelineCursor++
+		}
+
+	case "enter":
+		if m.timelineLen() == 0 {
+			return m, nil
+		}
+
+		var note string
+		var err error
+		if m.timelineCursor < len(m.game.Snapshots) {
+			turn := m.game.Snapshots[m.timelineCursor].State.Turns + 1
+			err = m.game.BranchFrom(m.timelineCursor)
+			note = fmt.Sprintf("[Branched from turn %d]", turn)
+		} else {
+			err = m.game.SwitchBranch(m.timelineCursor - len(m.game.Snapshots))
+			note = fmt.Sprintf("[Switched branch at turn %d]", m.game.Turns)
+		}
+
+		if err != nil {
+			m.content += fmt.Sprintf("\n[Timeline Error: %v]\n", err)
+		} else {
+			m.game.Output = ""
+			m.game.DescribeLocation()
+			m.game.ListObjects()
+			m.content += "\n" + dimStyle.Render(note) + "\n" + highlightOutput(m.game.Output, m.game) + "\n"
+			m.game.Output = ""
+			m.moveHistory = m.moveHistory[:0]
+		}
+
+		m.gameOutput.SetContent(m.content)
+		m.gameOutput.GotoBottom()
+		m.timelineMode = false
+
+	case "ctrl+c":
+		return m, m.quit()
+	}
+
+	return m, nil
+}
+
+// timelineView renders the timeline pane in place of the game output.
+func (m model) timelineView(width int) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("186"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	gainStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("82"))
+	lossStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	rows := make([]string, 0, m.timelineLen())
+
+	for _, entry := range m.game.Timeline() {
+		delta := dimStyle.Render("  0")
+		if entry.ScoreDelta > 0 {
+			delta = gainStyle.Render(fmt.Sprintf("%+3d", entry.ScoreDelta))
+		} else if entry.ScoreDelta < 0 {
+			delta = lossStyle.Render(fmt.Sprintf("%+3d", entry.ScoreDelta))
+		}
+		rows = append(rows, fmt.Sprintf("#%-4d %-16s %s  %s", entry.Turn, truncate(entry.Command, 16), delta, entry.LocName))
+	}
+
+	for i, branch := range m.game.Branches {
+		last := ""
+		if n := len(branch.Snapshots); n > 0 {
+			last = branch.Snapshots[n-1].Command
+		}
+		rows = append(rows, fmt.Sprintf("⎇ Branch %d: turn %d, last %q", i+1, branch.Tip.Turns, last))
+	}
+
+	// Show a window of rows around the cursor
+	start := 0
+	if m.timelineCursor >= maxTimelineRows {
+		start = m.timelineCursor - maxTimelineRows + 1
+	}
+	end := start + maxTimelineRows
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	lines := []string{titleStyle.Render("Timeline"), dimStyle.Render("↑/↓ select  enter branch/switch  esc close"), ""}
+	if len(rows) == 0 {
+		lines = append(lines, "No turns recorded yet.")
+	}
+	for i := start; i < end; i++ {
+		if i == len(m.game.Snapshots) {
+			lines = append(lines, "", titleStyle.Render("Branches"))
+		}
+		if i == m.timelineCursor {
+			lines = append(lines, cursorStyle.Render("> "+rows[i]))
+		} else {
+			lines = append(lines, "  "+rows[i])
+		}
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -1,11 +1,12 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/andrewsjg/goAdventure/advent"
-	"github.com/andrewsjg/goAdventure/ollama"
+	"github.com/andrewsjg/goAdventure/bench"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -20,7 +21,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.gameOutput.SetContent(m.content)
 			m.game.Output = ""
 		}
-		return m, tea.Quit
+		return m, m.quit()
 	}
 
 	// Perform the move if newloc has been set (but not if waiting for query response)
@@ -36,7 +37,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg: // Handle keyboard input
+		// The timeline takes over the keyboard while it is open
+		if m.timelineMode {
+			return m.updateTimeline(msg)
+		}
+
 		switch msg.String() {
+		case timelineKey: // Open the timeline
+			m = m.toggleTimeline()
+			return m, nil
+
 		case "up": // Browse command history (older)
 			if len(m.commandHistory) > 0 {
 				if m.historyIndex < len(m.commandHistory)-1 {
@@ -77,10 +87,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			// Generate new completions
+			// Generate new completions for the last word typed
 			if currentInput != "" {
 				m.completionBase = currentInput
 				m.completions = m.game.GetCompletions(currentInput)
+				if len(m.completions) == 0 {
+					// Nothing starts with it, so offer spelling corrections
+					m.completions = m.game.GetCorrections(currentInput)
+				}
 				if len(m.completions) > 0 {
 					m.completionIdx = 0
 					m.input.SetValue(m.completions[0])
@@ -146,7 +160,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Track location before command to detect movement
 				locBefore := m.game.Loc
 
-				err := m.game.ProcessCommand(userCmd)
+				err := m.game.ProcessInput(userCmd)
 
 				if err != nil {
 					m.output = fmt.Sprintf("Error: %s", err.Error())
@@ -177,7 +191,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "ctrl+c": // Handle Ctrl+C to quit
-			return m, tea.Quit
+			return m, m.quit()
 
 		default:
 			// Any other key clears the completion state (user is typing new text)
@@ -211,7 +225,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			} else {
 				// Process regular command
-				_ = m.game.ProcessCommand(scriptCmd)
+				_ = m.game.ProcessInput(scriptCmd)
 			}
 
 			// Add output and continue script execution
@@ -235,32 +249,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Set thinking state
 			m.aiIsThinking = true
 
-			// Track score before AI action for reward feedback
-			m.lastScore = m.game.GetScore()
-
 			// Build rich context for AI including reward feedback
-			ctx := &ollama.GameContext{
-				GameOutput:      m.game.Output,
-				LocationDesc:    m.game.GetLocationDescription(),
-				VisibleObjects:  m.game.GetVisibleObjects(),
-				Inventory:       m.game.InventoryDescriptions(),
-				Score:           m.game.GetScore(),
-				Turns:           int(m.game.Turns),
-				Hints:           m.game.GenerateHints(),
-				ValidActions:    m.game.GetAllVerbs(),
-				ValidDirections: m.game.GetAllDirections(),
-				ValidObjects:    m.game.GetInteractableObjects(),
-			}
-			if m.rewardTracker != nil {
-				ctx.RewardFeedback = m.rewardTracker.GetFeedback()
-			}
-
-			// Return both the AI call and spinner tick to keep spinner animating
-			aiCmd := func() tea.Msg {
-				cmd, thinking, err := m.aiPlayer.GetCommand(ctx)
-				return aiCommandMsg{command: cmd, thinking: thinking, err: err}
-			}
-			return m, tea.Batch(aiCmd, m.aiSpinner.Tick)
+			ctx := bench.GameContext(m.game, m.rewardTracker)
+
+			// Ask the AI in the background, streaming its reply back as it
+			// arrives, and keep the spinner animating meanwhile
+			stream := make(chan tea.Msg)
+			m.aiStream = stream
+			m.aiPartial = ""
+			go func(aiCtx context.Context) {
+				defer close(stream)
+				send := func(msg tea.Msg) {
+					select {
+					case stream <- msg:
+					case <-aiCtx.Done():
+					}
+				}
+				cmd, thinking, err := m.aiPlayer.GetCommandStream(aiCtx, ctx, func(response string) {
+					send(aiStreamMsg{response: response})
+				})
+				send(aiCommandMsg{command: cmd, thinking: thinking, err: err})
+			}(m.aiCtx)
+			return m, tea.Batch(waitForAI(stream), m.aiSpinner.Tick)
 		}
 
 	case spinner.TickMsg:
@@ -271,9 +281,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+	case aiStreamMsg:
+		// More of the AI's reply has arrived - show it and wait for the rest
+		m.aiPartial = msg.response
+		return m, waitForAI(m.aiStream)
+
 	case aiCommandMsg:
 		// AI command response received - stop thinking indicator
 		m.aiIsThinking = false
+		m.aiPartial = ""
 
 		if msg.err != nil {
 			m.content += fmt.Sprintf("\n[AI Error: %v]\n", msg.err)
@@ -323,11 +339,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.game.Output = ""
 		}
 
-		// Record reward for AI action
+		// Reward the AI action once it has played out
 		if m.rewardTracker != nil {
-			scoreAfter := m.game.GetScore()
-			died := m.game.GameOver
-			m.rewardTracker.RecordAction(msg.command, m.lastScore, scoreAfter, died)
+			m.rewardTracker.StartAction(int(locBefore), msg.command)
 		}
 
 		// Schedule next AI command if game not over
@@ -400,6 +414,19 @@ type aiCommandMsg struct {
 	err      error
 }
 
+// aiStreamMsg carries the AI's reply so far while it is being generated.
+type aiStreamMsg struct {
+	response string
+}
+
+// waitForAI returns a command that waits for the next message about the AI's
+// reply: more of it, or the finished command.
+func waitForAI(stream <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-stream
+	}
+}
+
 // aiTick returns a command that triggers AI command generation after a delay
 func aiTick(delay time.Duration) tea.Cmd {
 	return tea.Tick(delay, func(t time.Time) tea.Msg {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/andrewsjg/goAdventure/advent"
+	"github.com/andrewsjg/goAdventure/ollama"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -81,7 +83,13 @@ func (m model) View() string {
 	locationBox := locationStyle.Render(locationContent)
 
 	inputBox := inputStyle.Render(inputViewport.View())
+	if m.aiIsThinking && m.aiPartial != "" {
+		inputBox = inputStyle.Render(m.aiPartialView(innerWidth))
+	}
 	outputBox := outputStyle.Render(outputViewport.View())
+	if m.timelineMode {
+		outputBox = outputStyle.Render(m.timelineView(innerWidth))
+	}
 
 	mainColumn := fmt.Sprintf("%s\n%s\n%s", locationBox, outputBox, inputBox)
 
@@ -133,8 +141,58 @@ func (m model) View() string {
 
 	historyBox := historyStyle.Render(historyContent)
 
-	// Combine inventory and history into right column
-	rightColumn := lipgloss.JoinVertical(lipgloss.Top, inventoryBox, historyBox)
+	// Map of the explored cave around the current location, three cells wide
+	mapTitleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("186"))
+	mapCellWidth := (inventoryWidth - 2 - 2*advent.MAP_GAP) / 3
+	mapBody := "Nothing explored yet."
+	if len(m.game.MapEdges) > 0 {
+		mapBody = m.game.MapGrid(1, mapCellWidth)
+	}
+
+	mapContent := lipgloss.JoinVertical(lipgloss.Left,
+		mapTitleStyle.Render("Map\n"),
+		mapBody,
+	)
+
+	mapStyle := boxStyle.
+		AlignHorizontal(lipgloss.Left).
+		AlignVertical(lipgloss.Top).
+		Width(inventoryWidth)
+
+	mapBox := mapStyle.Render(mapContent)
+
+	// Ways out of the current location
+	exitsTitleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("186"))
+	exitsBody := "No known way out."
+	if exits := m.game.Exits(); len(exits) > 0 {
+		exitLines := make([]string, 0, len(exits))
+		for _, exit := range exits {
+			line := "• " + strings.ToLower(exit.Word)
+			if exit.Name != "" {
+				line += " → " + exit.Name
+			}
+			if exit.Sometimes {
+				line += " ?"
+			}
+			exitLines = append(exitLines, line)
+		}
+		exitsBody = strings.Join(exitLines, "\n")
+	}
+
+	exitsContent := lipgloss.JoinVertical(lipgloss.Left,
+		exitsTitleStyle.Render("Exits\n"),
+		exitsBody,
+	)
+
+	exitsStyle := boxStyle.
+		AlignHorizontal(lipgloss.Left).
+		AlignVertical(lipgloss.Top).
+		Width(inventoryWidth)
+
+	exitsBox := exitsStyle.Render(exitsContent)
+
+	// Combine inventory, exits, history and map into right column
+	rightColumn := lipgloss.JoinVertical(lipgloss.Top, inventoryBox, exitsBox, historyBox, mapBox)
 
 	gap := lipgloss.NewStyle().Width(gapWidth).Render("")
 
@@ -161,6 +219,7 @@ func (m model) View() string {
 	} else {
 		footerContent = fmt.Sprintf("Score: %d  |  Turns: %d", m.game.GetScore(), m.game.Turns)
 	}
+	footerContent += "  |  ctrl+t: timeline"
 	footerText := footerStyle.Render(footerContent)
 
 	// Title banner
@@ -183,3 +242,20 @@ func (m model) View() string {
 	return lipgloss.JoinVertical(lipgloss.Top, header, mainScreen, footerText)
 
 }
+
+// aiPartialView shows the AI's reply as it streams in, on the one line of the
+// input box: the command once it has started, otherwise the end of the
+// thinking if that is being shown.
+func (m model) aiPartialView(width int) string {
+	thinking, command := ollama.ParsePartial(m.aiPartial)
+	if command != "" || !m.showThinking {
+		return "> " + command
+	}
+
+	thinkingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Italic(true)
+	text := []rune(strings.Join(strings.Fields(thinking), " "))
+	if len(text) > width-1 {
+		text = append([]rune("…"), text[len(text)-(width-2):]...)
+	}
+	return thinkingStyle.Render(string(text))
+}